@@ -5,6 +5,7 @@ package m2x
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -18,9 +19,51 @@ const (
 )
 
 // Client represents a client for the M2X API (https://m2x.att.com/developer/documentation/overview)
+//
+// Each Client carries its own API key and headers, so several clients with
+// different keys may be used concurrently. Headers are applied to every
+// request after the default M2X headers and should not be modified while
+// requests are in flight.
 type Client struct {
-	APIBase string
-	Headers map[string]string
+	APIBase    string
+	APIKey     string
+	Headers    map[string]string
+	HTTPClient *http.Client
+	Retry      *RetryPolicy
+
+	// RateLimiter limits every request unless EndpointRateLimiters has a
+	// limiter for the class of the request
+	RateLimiter          *RateLimiter
+	EndpointRateLimiters map[EndpointClass]*RateLimiter
+}
+
+// Option configures a Client created with NewClient
+type Option func(*Client)
+
+// WithHTTPClient makes the client send every request through httpClient,
+// allowing timeouts, proxies, custom TLS and connection pooling to be controlled
+// by the caller
+//
+//		client := NewClient("<API-KEY>", WithHTTPClient(&http.Client{Timeout: 10 * time.Second}))
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithTransport makes the client send every request through the given RoundTripper.
+// Any HTTP client configured earlier is copied rather than modified.
+//
+//		client := NewClient("<API-KEY>", WithTransport(&http.Transport{Proxy: http.ProxyFromEnvironment}))
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		httpClient := &http.Client{}
+		if c.HTTPClient != nil {
+			*httpClient = *c.HTTPClient
+		}
+		httpClient.Transport = transport
+		c.HTTPClient = httpClient
+	}
 }
 
 // Status represents a status returned by the /status resource
@@ -30,17 +73,24 @@ type Status struct {
 }
 
 // APIKey is the key for the API
+//
+// Deprecated: the key is now carried on each Client (see Client.APIKey) and
+// this variable is no longer read or written by the library.
 var APIKey string
 
 // NewClient creates a NewClient for the M2X API
 //
 //		client := NewClient("<API-KEY>")
-func NewClient(apiKey string) *Client {
+func NewClient(apiKey string, options ...Option) *Client {
 	m2xClient := &Client{
-		APIBase: "http://api-m2x.att.com/v1",
-		Headers: make(map[string]string),
+		APIBase:    "http://api-m2x.att.com/v1",
+		APIKey:     apiKey,
+		Headers:    make(map[string]string),
+		HTTPClient: &http.Client{},
+	}
+	for _, option := range options {
+		option(m2xClient)
 	}
-	APIKey = apiKey
 	return m2xClient
 }
 
@@ -48,9 +98,17 @@ func NewClient(apiKey string) *Client {
 //
 //		result, err := client.Status()
 func (c *Client) Status() (*Status, error) {
-	result, _, err := get(c.APIBase + "/status")
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but uses ctx for the request
+func (c *Client) StatusContext(ctx context.Context) (*Status, error) {
+	result, _, err := c.get(ctx, c.APIBase+"/status")
+	if err != nil {
+		return nil, err
+	}
 	status := &Status{}
-	err = json.Unmarshal(result, &status)
+	err = json.Unmarshal(result.body, &status)
 	if err != nil {
 		return nil, err
 	}
@@ -59,57 +117,132 @@ func (c *Client) Status() (*Status, error) {
 
 // Provides a common facility for doing a DELETE on an M2X API resource
 //
-//		result, err := delete("http://api-m2x.att.com/v1/feeds", "1234")
-func delete(resource string, id string) ([]byte, int, error) {
-	httpClient := &http.Client{}
-	req, _ := http.NewRequest("DELETE", resource+"/"+id, nil)
-	return processRequest(req, httpClient)
+//		result, err := c.delete(ctx, "http://api-m2x.att.com/v1/feeds", "1234")
+func (c *Client) delete(ctx context.Context, resource string, id string) (*apiResponse, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", resource+"/"+id, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.processRequest(req)
+}
+
+// Provides a common facility for doing a DELETE with a body on an M2X API
+// resource. Takes JSON []byte for the data argument.
+//
+//		result, err := c.deleteData(ctx, "/feeds/1234/streams/temperature/values", valuesRange)
+func (c *Client) deleteData(ctx context.Context, resource string, data []byte) (*apiResponse, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", resource, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.processRequest(req)
 }
 
 // Provides a common facility for doing a GET on an M2X API resource
 //
-//		result, err := get("/status")
-func get(resource string) ([]byte, int, error) {
-	httpClient := &http.Client{}
-	req, _ := http.NewRequest("GET", resource, nil)
-	return processRequest(req, httpClient)
+//		result, err := c.get(ctx, "/status")
+func (c *Client) get(ctx context.Context, resource string) (*apiResponse, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", resource, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.processRequest(req)
 }
 
 // Provides a common facility for doing a POST on an M2X API resource. Takes
 // JSON []byte for the data argument.
 //
-//		result, err := post("/blueprints", blueprint)
-func post(resource string, data []byte) ([]byte, int, error) {
-	httpClient := &http.Client{}
-	req, _ := http.NewRequest("POST", resource, bytes.NewReader(data))
-	return processRequest(req, httpClient)
+//		result, err := c.post(ctx, "/blueprints", blueprint)
+func (c *Client) post(ctx context.Context, resource string, data []byte) (*apiResponse, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", resource, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.processRequest(req)
 }
 
 // Provides a common facility for doing a PUT on an M2X API resource. Takes
 // JSON []byte for the data argument.
 //
-//		result, err := put("/blueprints", blueprint)
-func put(resource string, data []byte) ([]byte, int, error) {
-	httpClient := &http.Client{}
-	req, _ := http.NewRequest("PUT", resource, bytes.NewReader(data))
-	return processRequest(req, httpClient)
+//		result, err := c.put(ctx, "/blueprints", blueprint)
+func (c *Client) put(ctx context.Context, resource string, data []byte) (*apiResponse, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", resource, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.processRequest(req)
 }
 
-func processRequest(req *http.Request, httpClient *http.Client) ([]byte, int, error) {
-	setHeaders(req)
+// apiResponse holds the body of an API response along with the request that
+// produced it, so errors can report where they came from
+type apiResponse struct {
+	body   []byte
+	method string
+	url    string
+}
+
+// Sends the request through the client's HTTP client, retrying it according
+// to the client's retry policy, and reads the response body
+func (c *Client) processRequest(req *http.Request) (*apiResponse, int, error) {
+	c.setHeaders(req)
+	policy := c.Retry
+	if policy == nil || !policy.allows(req.Method) {
+		body, statusCode, _, err := c.attempt(req)
+		return newAPIResponse(req, body), statusCode, err
+	}
+	for attempt := 1; ; attempt++ {
+		body, statusCode, header, err := c.attempt(req)
+		if attempt >= policy.MaxAttempts || !retryable(req.Context(), statusCode, err) {
+			return newAPIResponse(req, body), statusCode, err
+		}
+		if waitErr := sleepContext(req.Context(), policy.backoff(attempt, header)); waitErr != nil {
+			return nil, 0, waitErr
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// Wraps a response body with the method and URL of its request
+func newAPIResponse(req *http.Request, body []byte) *apiResponse {
+	return &apiResponse{
+		body:   body,
+		method: req.Method,
+		url:    req.URL.String(),
+	}
+}
+
+// Makes a single attempt at the request, once the rate limiter allows it
+func (c *Client) attempt(req *http.Request) ([]byte, int, http.Header, error) {
+	if limiter := c.limiterFor(req); limiter != nil {
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 	result, err := httpClient.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
-	body, _ := ioutil.ReadAll(result.Body)
+	body, err := ioutil.ReadAll(result.Body)
 	result.Body.Close()
-	return body, result.StatusCode, nil
+	if err != nil {
+		return nil, result.StatusCode, result.Header, err
+	}
+	return body, result.StatusCode, result.Header, nil
 }
 
-// Sets the custom headers required for the M2X API
-func setHeaders(req *http.Request) {
-	req.Header.Add("User-Agent", UserAgent)
-	req.Header.Add("X-M2X-KEY", APIKey)
+// Sets the headers required for the M2X API, followed by the client's custom headers
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("X-M2X-KEY", c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
 }
@@ -4,8 +4,18 @@
 package m2x
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/jsgoecke/m2x-go/m2xtest"
 )
 
 func TestNewClient(t *testing.T) {
@@ -15,10 +25,223 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+// Returns a client for the live API when M2X_API_KEY is set, or for an
+// in-memory fake of it otherwise
+func newTestClient(t *testing.T) *Client {
+	if apiKey := os.Getenv("M2X_API_KEY"); apiKey != "" {
+		return NewClient(apiKey)
+	}
+	server := m2xtest.NewServer()
+	t.Cleanup(server.Close)
+	client := NewClient(server.APIKey)
+	client.APIBase = server.URL
+	return client
+}
+
+// Returns a client for a functional test replaying the requests saved in the
+// cassette testdata/<name>.json, so the test runs without network access, and
+// fails if any of them is not made. Until the cassette is recorded the
+// synthetic testdata/<name>_synthetic.json, written by hand, is replayed
+// instead. With M2X_API_KEY set the requests are sent to the live API, and
+// with M2X_RECORD set as well they are recorded into testdata/<name>.json.
+func newCassetteClient(t *testing.T, name string) *Client {
+	path := filepath.Join("testdata", name+".json")
+	apiKey := os.Getenv("M2X_API_KEY")
+	if apiKey == "" {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join("testdata", name+"_synthetic.json")
+		}
+		cassette, err := m2xtest.NewCassette(path, m2xtest.Replay, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			for _, interaction := range cassette.Unused() {
+				t.Errorf("The request %s %s of %s was not replayed", interaction.Request.Method, interaction.Request.URL, path)
+			}
+		})
+		return NewClient("<API-KEY>", WithTransport(cassette))
+	}
+	if os.Getenv("M2X_RECORD") == "" {
+		return NewClient(apiKey)
+	}
+	cassette, err := m2xtest.NewCassette(path, m2xtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := cassette.Save(); err != nil {
+			t.Error(err)
+		}
+	})
+	return NewClient(apiKey, WithTransport(cassette))
+}
+
 func TestStatus(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	status, err := client.Status()
 	if err != nil || status.API != "OK" {
-		t.Errorf(err.Error())
+		t.Error(err)
+	}
+}
+
+func TestClientKeysAreIndependent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api":"` + r.Header.Get("X-M2X-KEY") + `","triggers":"` + r.Header.Get("X-Tenant") + `"}`))
+	}))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"key-one", "key-two", "key-three"} {
+		client := NewClient(key)
+		client.APIBase = server.URL
+		client.Headers["X-Tenant"] = "tenant-" + key
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(client *Client, key string) {
+				defer wg.Done()
+				status, err := client.Status()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if status.API != key || status.Triggers != "tenant-"+key {
+					t.Errorf("Expected key %s to be sent, got %s (%s)", key, status.API, status.Triggers)
+				}
+			}(client, key)
+		}
+	}
+	wg.Wait()
+}
+
+// recordedRequest is the last request received by a server from newRecordingServer
+type recordedRequest struct {
+	mu     sync.Mutex
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+func (r *recordedRequest) get() (string, string, string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.method, r.path, r.query, r.body
+}
+
+// Starts a server answering every request with statusCode and response, and
+// returns a client pointed at it along with the last request it received
+func newRecordingServer(statusCode int, response string) (*httptest.Server, *Client, *recordedRequest) {
+	last := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		last.mu.Lock()
+		last.method, last.path, last.query, last.body = r.Method, r.URL.EscapedPath(), r.URL.RawQuery, string(body)
+		last.mu.Unlock()
+		w.WriteHeader(statusCode)
+		w.Write([]byte(response))
+	}))
+	client := NewClient("1234")
+	client.APIBase = server.URL
+	return server, client, last
+}
+
+type countingTransport struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.mu.Lock()
+	ct.requests = append(ct.requests, req.Method+" "+req.URL.Path)
+	ct.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{}`))
+		case "DELETE":
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(201)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := NewClient("1234", WithHTTPClient(&http.Client{Timeout: 5 * time.Second}), WithTransport(transport))
+	client.APIBase = server.URL
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout of the HTTP client was not kept")
+	}
+
+	client.Feeds()
+	client.Blueprints()
+	client.CreateTrigger("/feeds/1234", map[string]string{"name": "foobar"})
+	client.DeleteKey("1234")
+	if len(transport.requests) != 4 {
+		t.Fatalf("Expected 4 requests through the transport, got %d", len(transport.requests))
+	}
+	if transport.requests[2] != "POST /feeds/1234/triggers" || transport.requests[3] != "DELETE /keys/1234" {
+		t.Errorf("Requests did not go through the transport properly: %v", transport.requests)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	calls := map[string]func(ctx context.Context) *ErrorMessage{
+		"FeedsContext": func(ctx context.Context) *ErrorMessage {
+			_, err := client.FeedsContext(ctx)
+			return err
+		},
+		"FeedStreamValuesContext": func(ctx context.Context) *ErrorMessage {
+			_, err := client.FeedStreamValuesContext(ctx, "/feeds/1234", "temperature")
+			return err
+		},
+		"BlueprintsContext": func(ctx context.Context) *ErrorMessage {
+			_, err := client.BlueprintsContext(ctx)
+			return err
+		},
+		"CreateTriggerContext": func(ctx context.Context) *ErrorMessage {
+			_, err := client.CreateTriggerContext(ctx, "/feeds/1234", map[string]string{"name": "foobar"})
+			return err
+		},
+		"DeleteKeyContext": func(ctx context.Context) *ErrorMessage {
+			return client.DeleteKeyContext(ctx, "1234")
+		},
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		errorMessage := call(ctx)
+		cancel()
+		if errorMessage == nil || !errors.Is(errorMessage, context.DeadlineExceeded) {
+			t.Errorf("%s did not return a deadline error: %v", name, errorMessage)
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Errorf("%s was not cancelled in time", name)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.StatusContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("StatusContext did not honor a cancelled context: %v", err)
 	}
 }
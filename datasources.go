@@ -4,6 +4,7 @@
 package m2x
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -28,8 +29,8 @@ type Blueprint struct {
 	URL         string     `json:"url"`
 	Key         string     `json:"key"`
 	Tags        []string   `json:"tags"`
-	Created     string     `json:"created"`
-	Updated     string     `json:"updated"`
+	Created     Timestamp  `json:"created"`
+	Updated     Timestamp  `json:"updated"`
 	Datasources Datasource `json:"datasources"`
 }
 
@@ -54,8 +55,8 @@ type Batch struct {
 	URL         string     `json:"url"`
 	Key         string     `json:"key"`
 	Tags        []string   `json:"tags"`
-	Created     string     `json:"created"`
-	Updated     string     `json:"updated"`
+	Created     Timestamp  `json:"created"`
+	Updated     Timestamp  `json:"updated"`
 	Datasources Datasource `json:"datasources"`
 }
 
@@ -66,6 +67,49 @@ type Datasource struct {
 	Unregistered int `json:"unregistered"`
 }
 
+// BlueprintParams holds the fields used to create or update a blueprint.
+// Empty fields are left out, so an update only changes the fields that are set.
+type BlueprintParams struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+// BatchParams holds the fields used to create or update a batch.
+// Empty fields are left out, so an update only changes the fields that are set.
+type BatchParams struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+// Checks the blueprint params, requiring a name and visibility on creation
+func (p *BlueprintParams) validate(create bool) Error {
+	if p == nil {
+		p = &BlueprintParams{}
+	}
+	return validateDatasource(p.Name, p.Visibility, create)
+}
+
+// Checks the batch params, requiring a name and visibility on creation
+func (p *BatchParams) validate(create bool) Error {
+	if p == nil {
+		p = &BatchParams{}
+	}
+	return validateDatasource(p.Name, p.Visibility, create)
+}
+
+// Checks the fields shared by blueprints and batches
+func validateDatasource(name string, visibility string, create bool) Error {
+	errs := Error{}
+	if create {
+		errs.require("name", name)
+		errs.require("visibility", visibility)
+	}
+	errs.oneOf("visibility", visibility, "public", "private")
+	return errs
+}
+
 // CreateBlueprint creates a new blueprint
 //
 //		blueprintData := make(map[string]string)
@@ -73,17 +117,53 @@ type Datasource struct {
 // 		blueprintData["description"] = "A blueprint for the Go lib for M2X"
 // 		blueprintData["visibility"] = "private"
 // 		blueprint, err := client.CreateBlueprint(blueprintData)
+//
+// Deprecated: use CreateBlueprintWithParams, which checks the data before sending it.
 func (c *Client) CreateBlueprint(blueprint map[string]string) (*Blueprint, *ErrorMessage) {
+	return c.CreateBlueprintContext(context.Background(), blueprint)
+}
+
+// CreateBlueprintContext is like CreateBlueprint but uses ctx for the request
+//
+// Deprecated: use CreateBlueprintWithParamsContext, which checks the data before sending it.
+func (c *Client) CreateBlueprintContext(ctx context.Context, blueprint map[string]string) (*Blueprint, *ErrorMessage) {
+	return c.createBlueprint(ctx, blueprint)
+}
+
+// CreateBlueprintWithParams creates a new blueprint, checking the params first
+//
+//		blueprint, err := client.CreateBlueprintWithParams(&BlueprintParams{
+//			Name:        "Go Blueprint",
+//			Description: "A blueprint for the Go lib for M2X",
+//			Visibility:  "private",
+//		})
+func (c *Client) CreateBlueprintWithParams(params *BlueprintParams) (*Blueprint, *ErrorMessage) {
+	return c.CreateBlueprintWithParamsContext(context.Background(), params)
+}
+
+// CreateBlueprintWithParamsContext is like CreateBlueprintWithParams but uses ctx for the request
+func (c *Client) CreateBlueprintWithParamsContext(ctx context.Context, params *BlueprintParams) (*Blueprint, *ErrorMessage) {
+	if errs := params.validate(true); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	return c.createBlueprint(ctx, params)
+}
+
+// Sends the data of CreateBlueprint, given either as a map or as params
+func (c *Client) createBlueprint(ctx context.Context, blueprint interface{}) (*Blueprint, *ErrorMessage) {
 	data, err := json.Marshal(blueprint)
+	if err != nil {
+		return nil, simpleErrorMessage(err, 0)
+	}
 
-	result, statusCode, err := post(c.APIBase+"/blueprints", data)
+	result, statusCode, err := c.post(ctx, c.APIBase+"/blueprints", data)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 
 	if statusCode == 201 {
 		newBlueprint := &Blueprint{}
-		err = json.Unmarshal(result, &newBlueprint)
+		err = json.Unmarshal(result.body, &newBlueprint)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -96,7 +176,12 @@ func (c *Client) CreateBlueprint(blueprint map[string]string) (*Blueprint, *Erro
 //
 //		err := client.DeleteBlueprint(blueprint.ID)
 func (c *Client) DeleteBlueprint(id string) *ErrorMessage {
-	result, statusCode, err := delete(c.APIBase+"/blueprints", id)
+	return c.DeleteBlueprintContext(context.Background(), id)
+}
+
+// DeleteBlueprintContext is like DeleteBlueprint but uses ctx for the request
+func (c *Client) DeleteBlueprintContext(ctx context.Context, id string) *ErrorMessage {
+	result, statusCode, err := c.delete(ctx, c.APIBase+"/blueprints", id)
 	if err != nil {
 		return simpleErrorMessage(err, statusCode)
 	}
@@ -110,12 +195,29 @@ func (c *Client) DeleteBlueprint(id string) *ErrorMessage {
 //
 //		blueprints, err := client.Blueprints()
 func (c *Client) Blueprints() (*Blueprints, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/blueprints")
+	return c.BlueprintsContext(context.Background())
+}
+
+// BlueprintsContext is like Blueprints but uses ctx for the request
+func (c *Client) BlueprintsContext(ctx context.Context) (*Blueprints, *ErrorMessage) {
+	return c.BlueprintsPageContext(ctx, nil)
+}
+
+// BlueprintsPage gets a single page of blueprints
+//
+//		blueprints, err := client.BlueprintsPage(&ListOptions{Page: 2, Limit: 10})
+func (c *Client) BlueprintsPage(opts *ListOptions) (*Blueprints, *ErrorMessage) {
+	return c.BlueprintsPageContext(context.Background(), opts)
+}
+
+// BlueprintsPageContext is like BlueprintsPage but uses ctx for the request
+func (c *Client) BlueprintsPageContext(ctx context.Context, opts *ListOptions) (*Blueprints, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+"/blueprints"+opts.query())
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseBlueprints(result)
+		data, err := parseBlueprints(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -128,12 +230,17 @@ func (c *Client) Blueprints() (*Blueprints, *ErrorMessage) {
 //
 //		blueprint, err := client.Blueprint("1234")
 func (c *Client) Blueprint(id string) (*Blueprint, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/blueprints/" + id)
+	return c.BlueprintContext(context.Background(), id)
+}
+
+// BlueprintContext is like Blueprint but uses ctx for the request
+func (c *Client) BlueprintContext(ctx context.Context, id string) (*Blueprint, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+"/blueprints/"+id)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseBlueprint(result)
+		data, err := parseBlueprint(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -146,14 +253,43 @@ func (c *Client) Blueprint(id string) (*Blueprint, *ErrorMessage) {
 //
 //		blueprintData["description"] = "A blueprint for the Go lib for AT&T M2X"
 //		err := client.UpdateBlueprint(blueprint.ID, blueprintData)
+//
+// Deprecated: use UpdateBlueprintWithParams, which checks the data before sending it.
 func (c *Client) UpdateBlueprint(id string, updateData map[string]string) *ErrorMessage {
+	return c.UpdateBlueprintContext(context.Background(), id, updateData)
+}
+
+// UpdateBlueprintContext is like UpdateBlueprint but uses ctx for the request
+//
+// Deprecated: use UpdateBlueprintWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateBlueprintContext(ctx context.Context, id string, updateData map[string]string) *ErrorMessage {
+	return c.updateBlueprint(ctx, id, updateData)
+}
+
+// UpdateBlueprintWithParams updates the fields of a blueprint set in params
+//
+//		err := client.UpdateBlueprintWithParams(blueprint.ID, &BlueprintParams{Description: "A blueprint for the Go lib for AT&T M2X"})
+func (c *Client) UpdateBlueprintWithParams(id string, params *BlueprintParams) *ErrorMessage {
+	return c.UpdateBlueprintWithParamsContext(context.Background(), id, params)
+}
+
+// UpdateBlueprintWithParamsContext is like UpdateBlueprintWithParams but uses ctx for the request
+func (c *Client) UpdateBlueprintWithParamsContext(ctx context.Context, id string, params *BlueprintParams) *ErrorMessage {
+	if errs := params.validate(false); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateBlueprint(ctx, id, params)
+}
+
+// Sends the data of UpdateBlueprint, given either as a map or as params
+func (c *Client) updateBlueprint(ctx context.Context, id string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, postErr := put(c.APIBase+"/blueprints/"+id, data)
+	result, statusCode, postErr := c.put(ctx, c.APIBase+"/blueprints/"+id, data)
 	if postErr != nil {
-		return simpleErrorMessage(err, statusCode)
+		return simpleErrorMessage(postErr, statusCode)
 	}
 
 	if statusCode == 204 {
@@ -169,20 +305,53 @@ func (c *Client) UpdateBlueprint(id string, updateData map[string]string) *Error
 // 		batchData["description"] = "A batch for the Go lib for M2X"
 // 		batchData["visibility"] = "private"
 // 		batch, err := client.CreateBatch(batch)
+//
+// Deprecated: use CreateBatchWithParams, which checks the data before sending it.
 func (c *Client) CreateBatch(batch map[string]string) (*Batch, *ErrorMessage) {
+	return c.CreateBatchContext(context.Background(), batch)
+}
+
+// CreateBatchContext is like CreateBatch but uses ctx for the request
+//
+// Deprecated: use CreateBatchWithParamsContext, which checks the data before sending it.
+func (c *Client) CreateBatchContext(ctx context.Context, batch map[string]string) (*Batch, *ErrorMessage) {
+	return c.createBatch(ctx, batch)
+}
+
+// CreateBatchWithParams creates a new batch, checking the params first
+//
+//		batch, err := client.CreateBatchWithParams(&BatchParams{
+//			Name:        "Go Batch",
+//			Description: "A batch for the Go lib for M2X",
+//			Visibility:  "private",
+//		})
+func (c *Client) CreateBatchWithParams(params *BatchParams) (*Batch, *ErrorMessage) {
+	return c.CreateBatchWithParamsContext(context.Background(), params)
+}
+
+// CreateBatchWithParamsContext is like CreateBatchWithParams but uses ctx for the request
+func (c *Client) CreateBatchWithParamsContext(ctx context.Context, params *BatchParams) (*Batch, *ErrorMessage) {
+	if errs := params.validate(true); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	return c.createBatch(ctx, params)
+}
+
+// Sends the data of CreateBatch, given either as a map or as params
+func (c *Client) createBatch(ctx context.Context, batch interface{}) (*Batch, *ErrorMessage) {
 	data, err := json.Marshal(batch)
 	if err != nil {
 		return nil, simpleErrorMessage(err, 0)
 	}
 
-	result, statusCode, err := post(c.APIBase+"/batches", data)
+	result, statusCode, err := c.post(ctx, c.APIBase+"/batches", data)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 
 	if statusCode == 201 {
 		newBatch := &Batch{}
-		err = json.Unmarshal(result, &newBatch)
+		err = json.Unmarshal(result.body, &newBatch)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -195,7 +364,12 @@ func (c *Client) CreateBatch(batch map[string]string) (*Batch, *ErrorMessage) {
 //
 //		err := client.DeleteBatch(batch.ID)
 func (c *Client) DeleteBatch(id string) (*Batch, *ErrorMessage) {
-	result, statusCode, err := delete(c.APIBase+"/batches", id)
+	return c.DeleteBatchContext(context.Background(), id)
+}
+
+// DeleteBatchContext is like DeleteBatch but uses ctx for the request
+func (c *Client) DeleteBatchContext(ctx context.Context, id string) (*Batch, *ErrorMessage) {
+	result, statusCode, err := c.delete(ctx, c.APIBase+"/batches", id)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
@@ -209,12 +383,29 @@ func (c *Client) DeleteBatch(id string) (*Batch, *ErrorMessage) {
 //
 //		batches, err := client.Batches()
 func (c *Client) Batches() (*Batches, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/batches")
+	return c.BatchesContext(context.Background())
+}
+
+// BatchesContext is like Batches but uses ctx for the request
+func (c *Client) BatchesContext(ctx context.Context) (*Batches, *ErrorMessage) {
+	return c.BatchesPageContext(ctx, nil)
+}
+
+// BatchesPage gets a single page of batches
+//
+//		batches, err := client.BatchesPage(&ListOptions{Page: 2, Limit: 10})
+func (c *Client) BatchesPage(opts *ListOptions) (*Batches, *ErrorMessage) {
+	return c.BatchesPageContext(context.Background(), opts)
+}
+
+// BatchesPageContext is like BatchesPage but uses ctx for the request
+func (c *Client) BatchesPageContext(ctx context.Context, opts *ListOptions) (*Batches, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+"/batches"+opts.query())
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseBatches(result)
+		data, err := parseBatches(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -227,12 +418,17 @@ func (c *Client) Batches() (*Batches, *ErrorMessage) {
 //
 //		batch, err := client.Batch("1234")
 func (c *Client) Batch(id string) (*Batch, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/batches/" + id)
+	return c.BatchContext(context.Background(), id)
+}
+
+// BatchContext is like Batch but uses ctx for the request
+func (c *Client) BatchContext(ctx context.Context, id string) (*Batch, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+"/batches/"+id)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseBatch(result)
+		data, err := parseBatch(result.body)
 		if err != nil {
 			return data, simpleErrorMessage(err, statusCode)
 		}
@@ -245,12 +441,41 @@ func (c *Client) Batch(id string) (*Batch, *ErrorMessage) {
 //
 //		batchData["description"] = "A batch for the Go lib for AT&T M2X"
 //		err := client.UpdateBatch(batch.ID, batchData)
+//
+// Deprecated: use UpdateBatchWithParams, which checks the data before sending it.
 func (c *Client) UpdateBatch(id string, updateData map[string]string) *ErrorMessage {
+	return c.UpdateBatchContext(context.Background(), id, updateData)
+}
+
+// UpdateBatchContext is like UpdateBatch but uses ctx for the request
+//
+// Deprecated: use UpdateBatchWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateBatchContext(ctx context.Context, id string, updateData map[string]string) *ErrorMessage {
+	return c.updateBatch(ctx, id, updateData)
+}
+
+// UpdateBatchWithParams updates the fields of a batch set in params
+//
+//		err := client.UpdateBatchWithParams(batch.ID, &BatchParams{Description: "A batch for the Go lib for AT&T M2X"})
+func (c *Client) UpdateBatchWithParams(id string, params *BatchParams) *ErrorMessage {
+	return c.UpdateBatchWithParamsContext(context.Background(), id, params)
+}
+
+// UpdateBatchWithParamsContext is like UpdateBatchWithParams but uses ctx for the request
+func (c *Client) UpdateBatchWithParamsContext(ctx context.Context, id string, params *BatchParams) *ErrorMessage {
+	if errs := params.validate(false); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateBatch(ctx, id, params)
+}
+
+// Sends the data of UpdateBatch, given either as a map or as params
+func (c *Client) updateBatch(ctx context.Context, id string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, postErr := put(c.APIBase+"/batches/"+id, data)
+	result, statusCode, postErr := c.put(ctx, c.APIBase+"/batches/"+id, data)
 	if postErr != nil {
 		return simpleErrorMessage(postErr, statusCode)
 	}
@@ -4,7 +4,6 @@
 package m2x
 
 import (
-	"os"
 	"testing"
 	"time"
 )
@@ -152,7 +151,7 @@ func TestParseBatch(t *testing.T) {
 }
 
 func TestListBlueprints(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	blueprints, _ := client.Blueprints()
 	if blueprints.CurrentPage != 1 {
 		t.Errorf("Did not fetch Blueprints properly")
@@ -160,7 +159,7 @@ func TestListBlueprints(t *testing.T) {
 }
 
 func TestCreateAndListAndUpdateAndDeleteBlueprint(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newCassetteClient(t, "blueprint")
 
 	// Create a new blueprint
 	blueprintData := make(map[string]string)
@@ -201,7 +200,7 @@ func TestCreateAndListAndUpdateAndDeleteBlueprint(t *testing.T) {
 }
 
 func TestListBatches(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	blueprints, _ := client.Batches()
 	if blueprints.CurrentPage != 1 {
 		t.Errorf("Did not fetch Batches properly")
@@ -209,7 +208,7 @@ func TestListBatches(t *testing.T) {
 }
 
 func TestCreateAndListAndUpdateAndDeleteBatch(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 
 	// Create a new batch
 	batchData := make(map[string]string)
@@ -250,7 +249,7 @@ func TestCreateAndListAndUpdateAndDeleteBatch(t *testing.T) {
 }
 
 func TestListBadBlueprintId(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	_, errorMessage := client.Blueprint("1234")
 	if errorMessage.StatusCode != 404 || errorMessage.Message != "The specified blueprint does not exist" {
 		t.Errorf("We did not get the proper error message or code back")
@@ -258,9 +257,47 @@ func TestListBadBlueprintId(t *testing.T) {
 }
 
 func TestListBadBatchId(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	_, errorMessage := client.Batch("1234")
 	if errorMessage.StatusCode != 404 || errorMessage.Message != "The specified batch does not exist" {
 		t.Errorf("We did not get the proper error message or code back")
 	}
 }
+
+func TestBlueprintParams(t *testing.T) {
+	server, client, last := newRecordingServer(201, `{"id":"1234","name":"Go Blueprint","visibility":"private"}`)
+	defer server.Close()
+
+	_, errorMessage := client.CreateBlueprintWithParams(&BlueprintParams{Name: "Go Blueprint", Visibility: "privat"})
+	if !IsValidation(errorMessage) || errorMessage.Errors["visibility"] == nil {
+		t.Errorf("An invalid visibility should not have been sent")
+	}
+	_, errorMessage = client.CreateBlueprintWithParams(&BlueprintParams{Description: "No name"})
+	if errorMessage == nil || len(errorMessage.FieldErrors()) != 2 {
+		t.Errorf("Name and visibility should be required on creation")
+	}
+	if method, _, _, _ := last.get(); method != "" {
+		t.Errorf("No request should have been sent")
+	}
+
+	blueprint, errorMessage := client.CreateBlueprintWithParams(&BlueprintParams{Name: "Go Blueprint", Visibility: "private"})
+	if errorMessage != nil || blueprint.ID != "1234" {
+		t.Errorf("Did not create a new blueprint properly")
+	}
+	if _, path, _, body := last.get(); path != "/blueprints" || body != `{"name":"Go Blueprint","visibility":"private"}` {
+		t.Errorf("Blueprint params were not sent properly: %s %s", path, body)
+	}
+}
+
+func TestUpdateBatchWithParams(t *testing.T) {
+	server, client, last := newRecordingServer(204, ``)
+	defer server.Close()
+
+	errorMessage := client.UpdateBatchWithParams("1234", &BatchParams{Description: "Updated description!"})
+	if errorMessage != nil {
+		t.Errorf("Did not update batch properly: %v", errorMessage)
+	}
+	if method, path, _, body := last.get(); method != "PUT" || path != "/batches/1234" || body != `{"description":"Updated description!"}` {
+		t.Errorf("Only the description should have been sent: %s %s %s", method, path, body)
+	}
+}
@@ -6,36 +6,205 @@ package m2x
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 )
 
-// ErrorMessage represents an API error message
+var (
+	// ErrNotFound matches API errors for resources that do not exist (404)
+	ErrNotFound = errors.New("m2x: not found")
+	// ErrUnauthorized matches API errors for missing or insufficient keys (401, 403)
+	ErrUnauthorized = errors.New("m2x: unauthorized")
+	// ErrRateLimited matches API errors for throttled requests (429)
+	ErrRateLimited = errors.New("m2x: rate limited")
+	// ErrValidation matches API errors for invalid request data (422), as well
+	// as request data rejected locally before being sent
+	ErrValidation = errors.New("m2x: validation failed")
+)
+
+// ErrorMessage represents an API error message. It implements error and
+// matches ErrNotFound, ErrUnauthorized, ErrRateLimited and ErrValidation
+// with errors.Is based on its StatusCode. Err holds the transport error, or
+// the error decoding a successful response, that caused it, if any, and is
+// returned by Unwrap. It is nil for errors answered by the API. Body, Method
+// and URL describe the failed request for diagnostics, Body holding the raw
+// response even when it is not JSON.
+//
+// API methods return a *ErrorMessage, which is nil on success. Compare it to
+// nil before storing it in an error variable, as a nil *ErrorMessage stored
+// in an error is not itself nil.
 type ErrorMessage struct {
 	Message    string `json:"message"`
-	StatusCode int
-	Errors     Error `json:"errors"`
-	Error      error
+	StatusCode int    `json:"-"`
+	Errors     Error  `json:"errors"`
+	Err        error  `json:"-"`
+	Body       []byte `json:"-"`
+	Method     string `json:"-"`
+	URL        string `json:"-"`
+}
+
+// Error holds the validation messages returned by the API for each field
+//
+//		{ "errors": { "name": ["can't be blank"], "latitude": ["is not a number"] } }
+type Error map[string][]string
+
+// FieldError is a single validation message for a field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// String returns the field followed by its message, e.g. "name can't be blank"
+func (f FieldError) String() string {
+	return f.Field + " " + f.Message
+}
+
+// Error returns the message of the error along with its status code
+func (e *ErrorMessage) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	if e.StatusCode == 0 {
+		return "m2x: " + e.Message
+	}
+	if e.Method != "" {
+		return fmt.Sprintf("m2x: %s %s: %s (status %d)", e.Method, e.URL, e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("m2x: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// FieldErrors lists every validation message of the error, sorted by field
+//
+//		for _, fieldError := range errorMessage.FieldErrors() {
+//			log.Println(fieldError)
+//		}
+func (e *ErrorMessage) FieldErrors() []FieldError {
+	if e == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var fieldErrors []FieldError
+	for _, field := range fields {
+		for _, message := range e.Errors[field] {
+			fieldErrors = append(fieldErrors, FieldError{Field: field, Message: message})
+		}
+	}
+	return fieldErrors
+}
+
+// Unwrap returns the transport or decoding error behind the error message
+func (e *ErrorMessage) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// Is reports whether the error message matches one of the sentinel errors
+//
+//		if errors.Is(err, m2x.ErrNotFound) { ... }
+func (e *ErrorMessage) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	case ErrRateLimited:
+		return e.StatusCode == 429
+	case ErrValidation:
+		return e.StatusCode == 422 || (e.StatusCode == 0 && len(e.Errors) > 0)
+	}
+	return false
 }
 
-// Error is the simple error message
-type Error struct {
-	Name []string `json:"name"`
+// IsNotFound reports whether err is an API error for a resource that does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error for a missing or insufficient key
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRateLimited reports whether err is an API error for a throttled request
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsValidation reports whether err is an API error for invalid request data
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
 }
 
 // Generates an error message based on a JSON return from the API
-func generateErrorMessage(data []byte, statusCode int) *ErrorMessage {
-	errorMessage := &ErrorMessage{}
-	json.Unmarshal(data, &errorMessage)
+func generateErrorMessage(result *apiResponse, statusCode int) *ErrorMessage {
+	errorMessage := &ErrorMessage{
+		Body:   result.body,
+		Method: result.method,
+		URL:    result.url,
+	}
+	// Bodies that are not JSON, e.g. the HTML page of a proxy, are only kept in Body
+	var decoded ErrorMessage
+	if len(result.body) > 0 && json.Unmarshal(result.body, &decoded) == nil {
+		errorMessage.Message = decoded.Message
+		errorMessage.Errors = decoded.Errors
+	}
 	errorMessage.StatusCode = statusCode
-	errorMessage.Error = errors.New(errorMessage.Message)
+	if errorMessage.Message == "" {
+		errorMessage.Message = http.StatusText(statusCode)
+	}
 	return errorMessage
 }
 
+// Adds a validation message for a field
+func (e Error) add(field string, message string) {
+	e[field] = append(e[field], message)
+}
+
+// Adds a validation message if a required field is empty
+func (e Error) require(field string, value string) {
+	if value == "" {
+		e.add(field, "can't be blank")
+	}
+}
+
+// Adds a validation message if a field is set to a value outside of allowed
+func (e Error) oneOf(field string, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.add(field, "must be one of "+strings.Join(allowed, ", "))
+}
+
+// Generates an error message for request data that failed local validation
+func validationErrorMessage(errs Error) *ErrorMessage {
+	return &ErrorMessage{
+		Message: "Validation Failed",
+		Errors:  errs,
+	}
+}
+
 // Generates an error message without a JSON return from the API
 func simpleErrorMessage(err error, statusCode int) *ErrorMessage {
 	errorMessage := &ErrorMessage{
 		Message:    err.Error(),
 		StatusCode: statusCode,
-		Error:      err,
+		Err:        err,
 	}
 	return errorMessage
 }
@@ -4,6 +4,10 @@
 package m2x
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"testing"
 )
 
@@ -14,3 +18,83 @@ func TestParseErrorMessage(t *testing.T) {
 		t.Errorf("Error message did not parse properly")
 	}
 }
+
+func TestGenerateErrorMessage(t *testing.T) {
+	result := &apiResponse{
+		body:   []byte(`{ "message": "The specified feed does not exist" }`),
+		method: "GET",
+		url:    "http://api-m2x.att.com/v1/feeds/1234",
+	}
+	errorMessage := generateErrorMessage(result, 404)
+	if errorMessage.Error() != "m2x: GET http://api-m2x.att.com/v1/feeds/1234: The specified feed does not exist (status 404)" {
+		t.Errorf("Error did not format properly: %s", errorMessage.Error())
+	}
+	if errorMessage.Unwrap() != nil {
+		t.Errorf("An API error should not have a cause")
+	}
+
+	errorMessage = generateErrorMessage(&apiResponse{body: []byte(`<html>Bad Gateway</html>`)}, 502)
+	var syntaxErr *json.SyntaxError
+	if errorMessage.Message != "Bad Gateway" || errorMessage.Unwrap() != nil || errors.As(errorMessage, &syntaxErr) {
+		t.Errorf("A body that is not JSON should not be the cause of the error: %v", errorMessage.Unwrap())
+	}
+	if string(errorMessage.Body) != `<html>Bad Gateway</html>` {
+		t.Errorf("A body that is not JSON should be kept in Body: %s", errorMessage.Body)
+	}
+}
+
+func TestErrorMessageClassifiers(t *testing.T) {
+	var err error = generateErrorMessage(&apiResponse{}, 404)
+	if !IsNotFound(err) || IsUnauthorized(err) || IsRateLimited(err) || IsValidation(err) {
+		t.Errorf("404 was not classified properly")
+	}
+	if !IsUnauthorized(generateErrorMessage(&apiResponse{}, 401)) || !IsUnauthorized(generateErrorMessage(&apiResponse{}, 403)) {
+		t.Errorf("401 and 403 were not classified properly")
+	}
+	if !IsRateLimited(generateErrorMessage(&apiResponse{}, 429)) || !IsValidation(generateErrorMessage(&apiResponse{}, 422)) {
+		t.Errorf("429 and 422 were not classified properly")
+	}
+
+	wrapped := fmt.Errorf("listing feeds: %w", generateErrorMessage(&apiResponse{}, 404))
+	var errorMessage *ErrorMessage
+	if !errors.As(wrapped, &errorMessage) || errorMessage.StatusCode != 404 || !IsNotFound(wrapped) {
+		t.Errorf("A wrapped error message was not found")
+	}
+
+	var nilMessage *ErrorMessage
+	if IsNotFound(nilMessage) || nilMessage.Error() != "<nil>" {
+		t.Errorf("A nil error message should not match anything")
+	}
+}
+
+func TestSimpleErrorMessageUnwrap(t *testing.T) {
+	cause := io.ErrUnexpectedEOF
+	errorMessage := simpleErrorMessage(cause, 0)
+	if !errors.Is(errorMessage, io.ErrUnexpectedEOF) || errorMessage.Error() != "m2x: unexpected EOF" {
+		t.Errorf("The cause was not unwrapped properly")
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	data := `
+	{
+	  "message": "Validation Failed",
+	  "errors": {
+	    "name": ["can't be blank"],
+	    "callback_url": ["is not a valid URL", "is too long"]
+	  }
+	}`
+	result := &apiResponse{body: []byte(data), method: "POST", url: "http://api-m2x.att.com/v1/feeds/1234/triggers"}
+	errorMessage := generateErrorMessage(result, 422)
+	if !IsValidation(errorMessage) || errorMessage.Method != "POST" || string(errorMessage.Body) != data {
+		t.Errorf("Error message did not keep the request details")
+	}
+
+	fieldErrors := errorMessage.FieldErrors()
+	if len(fieldErrors) != 3 {
+		t.Fatalf("Expected 3 field errors, got %d", len(fieldErrors))
+	}
+	if fieldErrors[0].String() != "callback_url is not a valid URL" || fieldErrors[2].Field != "name" {
+		t.Errorf("Field errors did not parse properly: %v", fieldErrors)
+	}
+}
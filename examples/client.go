@@ -4,6 +4,7 @@ import (
 	"github.com/jsgoecke/m2x-go"
 	"log"
 	"os"
+	"time"
 )
 
 func main() {
@@ -11,65 +12,63 @@ func main() {
 	client := m2x.NewClient(os.Getenv("M2X_API_KEY"))
 
 	// Create a blueprint
-	blueprintData := make(map[string]string)
-	blueprintData["name"] = "Go Blueprint"
-	blueprintData["description"] = "A blueprint for the Go lib for M2X"
-	blueprintData["visibility"] = "private"
-
-	blueprint, errorMessage := client.CreateBlueprint(blueprintData)
+	blueprint, errorMessage := client.CreateBlueprintWithParams(&m2x.BlueprintParams{
+		Name:        "Go Blueprint",
+		Description: "A blueprint for the Go lib for M2X",
+		Visibility:  "private",
+	})
 	if errorMessage != nil {
-		log.Println(errorMessage)
+		log.Fatal(errorMessage)
 	}
 
 	// Update a bluprint
-	blueprintData["description"] = "A blueprint for the Go lib for AT&T M2X"
-	errorMessage = client.UpdateBlueprint(blueprint.Id, blueprintData)
+	errorMessage = client.UpdateBlueprintWithParams(blueprint.ID, &m2x.BlueprintParams{
+		Description: "A blueprint for the Go lib for AT&T M2X",
+	})
 
 	// Create a stream
-	streamData := make(map[string]interface{})
-	unit := make(map[string]string)
-	unit["label"] = "celcius"
-	unit["symbol"] = "C"
-	streamData["unit"] = unit
-	errorMessage = client.UpdateFeedStream(blueprint.Feed, "temperature", streamData)
+	errorMessage = client.UpdateFeedStreamWithParams(blueprint.Feed, "temperature", &m2x.StreamParams{
+		Unit: &m2x.Unit{Label: "celcius", Symbol: "C"},
+	})
 	if errorMessage != nil {
 		log.Println("Error creating stream")
 	}
 
 	//Update location of the feed stream
-	loc := make(map[string]interface{})
-	loc["name"] = "Storage Room in Sevilla, Spain"
-	loc["latitude"] = "37.383055"
-	loc["longitude"] = "-5.996392"
-	loc["elevation"] = "5"
-	errorMessage = client.UpdateFeedLocation(blueprint.Feed, loc)
+	errorMessage = client.UpdateFeedLocationWithParams(blueprint.Feed, &m2x.LocationParams{
+		Name:      "Storage Room in Sevilla, Spain",
+		Latitude:  "37.383055",
+		Longitude: "-5.996392",
+		Elevation: "5",
+	})
 	if errorMessage != nil {
 		log.Println("Error updating location")
 	}
 
 	// Create a trigger for the feed
-	triggerData := make(map[string]string)
-	triggerData["name"] = "foobar"
-	triggerData["stream"] = "temperature"
-	triggerData["condition"] = ">"
-	triggerData["value"] = "30"
-	triggerData["callback_url"] = "http://45bad07a.ngrok.com/streamEvent"
-	triggerData["status"] = "enabled"
-	_, errorMessage = client.CreateTrigger(blueprint.Feed, triggerData)
+	_, errorMessage = client.CreateTriggerWithParams(blueprint.Feed, &m2x.TriggerParams{
+		Name:        "foobar",
+		Stream:      "temperature",
+		Condition:   ">",
+		Value:       "30",
+		CallbackURL: "http://45bad07a.ngrok.com/streamEvent",
+		Status:      "enabled",
+	})
 	if errorMessage != nil {
 		log.Println("Error creating trigger")
 	}
 
 	// Update stream with data
 	values := make(map[string]interface{})
+	now := time.Now()
 	values["values"] = []*m2x.Value{
-		{"2013-09-09T19:15:00Z", "32"},
-		{"2013-09-09T19:16:00Z", "28 "},
-		{"2013-09-09T19:17:00Z", "25"},
-		{"2013-09-09T19:17:00Z", "40"},
+		{At: m2x.NewTimestamp(now.Add(-3 * time.Minute)), Value: m2x.Float64Value(32)},
+		{At: m2x.NewTimestamp(now.Add(-2 * time.Minute)), Value: m2x.Float64Value(28)},
+		{At: m2x.NewTimestamp(now.Add(-time.Minute)), Value: m2x.Float64Value(25)},
+		{At: m2x.NewTimestamp(now), Value: m2x.Float64Value(40)},
 	}
 	errorMessage = client.UpdateFeedStreamValues(blueprint.Feed, "temperature", values)
 
 	// Delete the blueprint
-	client.DeleteBlueprint(blueprint.Id)
+	client.DeleteBlueprint(blueprint.ID)
 }
@@ -1,29 +1,27 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/codegangsta/martini"
-	"github.com/jsgoecke/m2x-go"
-	"io/ioutil"
 	"log"
 	"net/http"
+
+	"github.com/jsgoecke/m2x-go"
+	"github.com/jsgoecke/m2x-go/m2xwebhook"
 )
 
 func main() {
-	m := martini.Classic()
-	m.Post("/streamEvent", streamRequestHandler)
-	http.ListenAndServe(":3000", m)
+	hook := m2xwebhook.NewHandler(m2xwebhook.WithErrorHandler(func(r *http.Request, err error) {
+		log.Println(err)
+	}))
+	hook.Handle(m2xwebhook.Match{}, streamEventHandler)
+	http.Handle("/streamEvent", hook)
+	log.Fatal(http.ListenAndServe(":3000", nil))
 }
 
-func streamRequestHandler(w http.ResponseWriter, r *http.Request) {
-	body, _ := ioutil.ReadAll(r.Body)
-	r.Body.Close()
-	triggerEvent, err := m2x.ParseTriggerEvent(body)
-	if err != nil {
-		log.Println(err)
-	} else {
-		log.Println("Received trigger event!")
-		jsonData, _ := json.MarshalIndent(triggerEvent, "", "    ")
-		log.Println(string(jsonData[:]))
-	}
+func streamEventHandler(ctx context.Context, triggerEvent *m2x.TriggerEvent) error {
+	log.Println("Received trigger event!")
+	jsonData, _ := json.MarshalIndent(triggerEvent, "", "    ")
+	log.Println(string(jsonData[:]))
+	return nil
 }
@@ -4,7 +4,13 @@
 package m2x
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Feeds represents a collection of feeds resource (https://m2x.att.com/developer/documentation/feed)
@@ -27,10 +33,10 @@ type Location struct {
 
 // Waypoint represents a waypoint
 type Waypoint struct {
-	Timestamp string `json:"timestamp"`
-	Latitude  string `json:"latitude"`
-	Longitude string `json:"longitude"`
-	Elevation string `json:"elevation"`
+	Timestamp Timestamp `json:"timestamp"`
+	Latitude  string    `json:"latitude"`
+	Longitude string    `json:"longitude"`
+	Elevation string    `json:"elevation"`
 }
 
 // Feed represents an individual feed
@@ -44,8 +50,8 @@ type Feed struct {
 	Tags        []string  `json:"tags"`
 	URL         string    `json:"url"`
 	Key         string    `json:"key"`
-	Created     string    `json:"created"`
-	Updated     string    `json:"updated"`
+	Created     Timestamp `json:"created"`
+	Updated     Timestamp `json:"updated"`
 	Location    Location  `json:"location"`
 	Streams     []Stream  `json:"streams"`
 	Triggers    []Trigger `json:"triggers"`
@@ -54,27 +60,32 @@ type Feed struct {
 // Stream represents a tream
 type Stream struct {
 	Name    string      `json:"name"`
-	Value   interface{} `json:"value"`
-	Min     interface{} `json:"min"`
-	Max     interface{} `json:"max"`
+	Value   StreamValue `json:"value"`
+	Min     StreamValue `json:"min"`
+	Max     StreamValue `json:"max"`
 	Unit    Unit        `json:"unit"`
 	URL     string      `json:"url"`
-	Created string      `json:"created"`
-	Updated string      `json:"updated"`
+	Created Timestamp   `json:"created"`
+	Updated Timestamp   `json:"updated"`
+}
+
+// Streams represents the collection of streams of a feed
+type Streams struct {
+	Streams []Stream `json:"streams"`
 }
 
 // Values represents a collection of values
 type Values struct {
-	Start  string `json:"start"`
-	End    string `json:"end"`
-	Limit  int    `json:"limit"`
+	Start  Timestamp `json:"start"`
+	End    Timestamp `json:"end"`
+	Limit  int       `json:"limit"`
 	Values []Value
 }
 
 // Value represents a value
 type Value struct {
-	At    string `json:"at"`
-	Value string `json:"value"`
+	At    Timestamp   `json:"at"`
+	Value StreamValue `json:"value"`
 }
 
 // Unit represents a request
@@ -90,22 +101,283 @@ type Requests struct {
 
 // Request represents a request
 type Request struct {
-	At     string `json:"at"`
-	Status int    `json:"status"`
-	Method string `json:"method"`
-	Path   string `json:"path"`
+	At     Timestamp `json:"at"`
+	Status int       `json:"status"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+}
+
+// FeedQuery holds the search filters of the /feeds resource. Feeds near a
+// location are found by setting Latitude, Longitude and Distance together.
+//
+//		query := &FeedQuery{
+//			Type:         "batch",
+//			Visibility:   "private",
+//			Tags:         []string{"warehouse"},
+//			Latitude:     "37.383055",
+//			Longitude:    "-5.996392",
+//			Distance:     "5",
+//			DistanceUnit: "km",
+//		}
+type FeedQuery struct {
+	ListOptions
+	Query        string
+	Type         string
+	Tags         []string
+	Visibility   string
+	Latitude     string
+	Longitude    string
+	Distance     string
+	DistanceUnit string
+}
+
+// Checks the feed query, which needs a complete set of geo filters if any
+func (q *FeedQuery) validate() Error {
+	errs := Error{}
+	if q == nil {
+		return errs
+	}
+	errs.oneOf("type", q.Type, "blueprint", "batch", "datasource")
+	errs.oneOf("visibility", q.Visibility, "public", "private")
+	errs.oneOf("distance_unit", q.DistanceUnit, "mi", "miles", "km")
+	if q.Latitude != "" || q.Longitude != "" || q.Distance != "" {
+		errs.require("latitude", q.Latitude)
+		errs.require("longitude", q.Longitude)
+		errs.require("distance", q.Distance)
+		checkCoordinate(errs, "latitude", q.Latitude, 90)
+		checkCoordinate(errs, "longitude", q.Longitude, 180)
+		if q.Distance != "" {
+			if distance, err := strconv.ParseFloat(q.Distance, 64); err != nil || distance < 0 {
+				errs.add("distance", "is not a positive number")
+			}
+		}
+	}
+	return errs
+}
+
+// Returns the query string for the filters, starting with "?" if not empty
+func (q *FeedQuery) query() string {
+	if q == nil {
+		return ""
+	}
+	params := q.ListOptions.values()
+	setParam(params, "q", q.Query)
+	setParam(params, "type", q.Type)
+	setParam(params, "tags", strings.Join(q.Tags, ","))
+	setParam(params, "visibility", q.Visibility)
+	setParam(params, "latitude", q.Latitude)
+	setParam(params, "longitude", q.Longitude)
+	setParam(params, "distance", q.Distance)
+	setParam(params, "distance_unit", q.DistanceUnit)
+	if len(params) > 0 {
+		return "?" + params.Encode()
+	}
+	return ""
+}
+
+// Number of values returned by the API when no limit is given
+const defaultValuesLimit = 100
+
+// ValuesQuery selects the values of a stream within a time window. Zero times
+// leave the window open on that side.
+type ValuesQuery struct {
+	Start time.Time
+	End   time.Time
+	Limit int
+	// Order is either "asc" or "desc"
+	Order string
+}
+
+// Checks the values query
+func (q *ValuesQuery) validate() Error {
+	errs := Error{}
+	if q == nil {
+		return errs
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.End.Before(q.Start) {
+		errs.add("end", "must not be before start")
+	}
+	if q.Limit < 0 {
+		errs.add("limit", "must not be negative")
+	}
+	errs.oneOf("order", q.Order, "asc", "desc")
+	return errs
+}
+
+// Returns the query string for the window, starting with "?" if not empty
+func (q *ValuesQuery) query() string {
+	if q == nil {
+		return ""
+	}
+	params := url.Values{}
+	if !q.Start.IsZero() {
+		params.Set("start", q.Start.UTC().Format(time.RFC3339Nano))
+	}
+	if !q.End.IsZero() {
+		params.Set("end", q.End.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	setParam(params, "order", q.Order)
+	if len(params) > 0 {
+		return "?" + params.Encode()
+	}
+	return ""
+}
+
+// Bounds sent for the open sides of a confirmed ValuesRange
+var (
+	openRangeFrom = time.Unix(0, 0).UTC()
+	openRangeEnd  = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+)
+
+// ValuesRange selects the values of a stream to delete, from From to End
+// inclusive. A zero time leaves the range open on that side, which deletes
+// every value before End or after From, so it has to be confirmed with
+// ConfirmOpenFrom or ConfirmOpenEnd.
+type ValuesRange struct {
+	From time.Time
+	End  time.Time
+	// ConfirmOpenFrom allows a zero From, deleting every value up to End
+	ConfirmOpenFrom bool
+	// ConfirmOpenEnd allows a zero End, deleting every value from From onwards
+	ConfirmOpenEnd bool
+}
+
+// Checks the values range, refusing open sides that were not confirmed
+func (r *ValuesRange) validate() Error {
+	errs := Error{}
+	if r == nil {
+		r = &ValuesRange{}
+	}
+	if r.From.IsZero() && !r.ConfirmOpenFrom {
+		errs.add("from", "is required unless ConfirmOpenFrom is set")
+	}
+	if r.End.IsZero() && !r.ConfirmOpenEnd {
+		errs.add("end", "is required unless ConfirmOpenEnd is set")
+	}
+	if !r.From.IsZero() && !r.End.IsZero() && r.End.Before(r.From) {
+		errs.add("end", "must not be before from")
+	}
+	return errs
+}
+
+// Returns the body of the delete request, filling in the open sides
+func (r *ValuesRange) body() map[string]Timestamp {
+	from, end := r.From, r.End
+	if from.IsZero() {
+		from = openRangeFrom
+	}
+	if end.IsZero() {
+		end = openRangeEnd
+	}
+	return map[string]Timestamp{"from": NewTimestamp(from), "end": NewTimestamp(end)}
+}
+
+// LocationParams holds the fields used to set the location of a feed.
+// Coordinates are given in decimal degrees and the elevation in meters.
+type LocationParams struct {
+	Name      string `json:"name,omitempty"`
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+	Elevation string `json:"elevation,omitempty"`
+}
+
+// StreamParams holds the fields used to create or update a stream
+type StreamParams struct {
+	Unit *Unit `json:"unit,omitempty"`
+}
+
+// Checks the location params, which always require coordinates
+func (p *LocationParams) validate() Error {
+	if p == nil {
+		p = &LocationParams{}
+	}
+	errs := Error{}
+	errs.require("latitude", p.Latitude)
+	errs.require("longitude", p.Longitude)
+	checkCoordinate(errs, "latitude", p.Latitude, 90)
+	checkCoordinate(errs, "longitude", p.Longitude, 180)
+	if p.Elevation != "" {
+		if _, err := strconv.ParseFloat(p.Elevation, 64); err != nil {
+			errs.add("elevation", "is not a number")
+		}
+	}
+	return errs
+}
+
+// Checks that a coordinate is a number within [-limit, limit]
+func checkCoordinate(errs Error, field string, value string, limit float64) {
+	if value == "" {
+		return
+	}
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		errs.add(field, "is not a number")
+	} else if coordinate < -limit || coordinate > limit {
+		errs.add(field, "must be between "+strconv.FormatFloat(-limit, 'f', -1, 64)+" and "+strconv.FormatFloat(limit, 'f', -1, 64))
+	}
+}
+
+// Checks the stream params along with the name of the stream. The unit is
+// optional, but must have both a label and a symbol when given.
+func (p *StreamParams) validate(name string) Error {
+	errs := Error{}
+	errs.require("name", name)
+	if p != nil && p.Unit != nil {
+		errs.require("unit.label", p.Unit.Label)
+		errs.require("unit.symbol", p.Unit.Symbol)
+	}
+	return errs
 }
 
 // Feeds gets a list of feeds
 //
 //		feeds, err := client.Feeds()
 func (c *Client) Feeds() (*Feeds, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/feeds")
+	return c.FeedsContext(context.Background())
+}
+
+// FeedsContext is like Feeds but uses ctx for the request
+func (c *Client) FeedsContext(ctx context.Context) (*Feeds, *ErrorMessage) {
+	return c.FeedsPageContext(ctx, nil)
+}
+
+// FeedsPage gets a single page of feeds
+//
+//		feeds, err := client.FeedsPage(&ListOptions{Page: 2, Limit: 10})
+func (c *Client) FeedsPage(opts *ListOptions) (*Feeds, *ErrorMessage) {
+	return c.FeedsPageContext(context.Background(), opts)
+}
+
+// FeedsPageContext is like FeedsPage but uses ctx for the request
+func (c *Client) FeedsPageContext(ctx context.Context, opts *ListOptions) (*Feeds, *ErrorMessage) {
+	query := &FeedQuery{}
+	if opts != nil {
+		query.ListOptions = *opts
+	}
+	return c.SearchFeedsContext(ctx, query)
+}
+
+// SearchFeeds gets a page of the feeds matching the query
+//
+//		feeds, err := client.SearchFeeds(&FeedQuery{Visibility: "private", Tags: []string{"warehouse"}})
+func (c *Client) SearchFeeds(query *FeedQuery) (*Feeds, *ErrorMessage) {
+	return c.SearchFeedsContext(context.Background(), query)
+}
+
+// SearchFeedsContext is like SearchFeeds but uses ctx for the request
+func (c *Client) SearchFeedsContext(ctx context.Context, query *FeedQuery) (*Feeds, *ErrorMessage) {
+	if errs := query.validate(); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	result, statusCode, err := c.get(ctx, c.APIBase+"/feeds"+query.query())
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseFeeds(result)
+		data, err := parseFeeds(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -118,12 +390,20 @@ func (c *Client) Feeds() (*Feeds, *ErrorMessage) {
 //
 //		feed, err := client.Feed("/feeds/1234")
 func (c *Client) Feed(resource string) (*Feed, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource)
+	return c.FeedContext(context.Background(), resource)
+}
+
+// FeedContext is like Feed but uses ctx for the request
+func (c *Client) FeedContext(ctx context.Context, resource string) (*Feed, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, _ := parseFeed(result)
+		data, err := parseFeed(result.body)
+		if err != nil {
+			return nil, simpleErrorMessage(err, statusCode)
+		}
 		return data, nil
 	}
 	return nil, generateErrorMessage(result, statusCode)
@@ -133,13 +413,18 @@ func (c *Client) Feed(resource string) (*Feed, *ErrorMessage) {
 //
 //		feed, err := client.FeedLocation("/feeds/1234")
 func (c *Client) FeedLocation(resource string) (*Location, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/location")
+	return c.FeedLocationContext(context.Background(), resource)
+}
+
+// FeedLocationContext is like FeedLocation but uses ctx for the request
+func (c *Client) FeedLocationContext(ctx context.Context, resource string) (*Location, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/location")
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
 		location := &Location{}
-		err := json.Unmarshal(result, &location)
+		err := json.Unmarshal(result.body, &location)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -156,12 +441,46 @@ func (c *Client) FeedLocation(resource string) (*Location, *ErrorMessage) {
 // 		loc["longitude"] = "-5.996392"
 // 		loc["elevation"] = "5"
 // 		err := client.UpdateFeedLocation("/feeds/1234", loc)
+//
+// Deprecated: use UpdateFeedLocationWithParams, which checks the data before sending it.
 func (c *Client) UpdateFeedLocation(resource string, updateData map[string]interface{}) *ErrorMessage {
+	return c.UpdateFeedLocationContext(context.Background(), resource, updateData)
+}
+
+// UpdateFeedLocationContext is like UpdateFeedLocation but uses ctx for the request
+//
+// Deprecated: use UpdateFeedLocationWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateFeedLocationContext(ctx context.Context, resource string, updateData map[string]interface{}) *ErrorMessage {
+	return c.updateFeedLocation(ctx, resource, updateData)
+}
+
+// UpdateFeedLocationWithParams creates or updates a feed location, checking the params first
+//
+//		err := client.UpdateFeedLocationWithParams("/feeds/1234", &LocationParams{
+//			Name:      "Storage Room in Sevilla, Spain",
+//			Latitude:  "37.383055",
+//			Longitude: "-5.996392",
+//			Elevation: "5",
+//		})
+func (c *Client) UpdateFeedLocationWithParams(resource string, params *LocationParams) *ErrorMessage {
+	return c.UpdateFeedLocationWithParamsContext(context.Background(), resource, params)
+}
+
+// UpdateFeedLocationWithParamsContext is like UpdateFeedLocationWithParams but uses ctx for the request
+func (c *Client) UpdateFeedLocationWithParamsContext(ctx context.Context, resource string, params *LocationParams) *ErrorMessage {
+	if errs := params.validate(); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateFeedLocation(ctx, resource, params)
+}
+
+// Sends the data of UpdateFeedLocation, given either as a map or as params
+func (c *Client) updateFeedLocation(ctx context.Context, resource string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, putErr := put(c.APIBase+resource+"/location", data)
+	result, statusCode, putErr := c.put(ctx, c.APIBase+resource+"/location", data)
 	if putErr != nil {
 		return simpleErrorMessage(putErr, statusCode)
 	}
@@ -172,16 +491,63 @@ func (c *Client) UpdateFeedLocation(resource string, updateData map[string]inter
 	return generateErrorMessage(result, statusCode)
 }
 
+// FeedStreams lists the streams of a feed
+//
+//		streams, err := client.FeedStreams("/feeds/1234")
+func (c *Client) FeedStreams(resource string) (*Streams, *ErrorMessage) {
+	return c.FeedStreamsContext(context.Background(), resource)
+}
+
+// FeedStreamsContext is like FeedStreams but uses ctx for the request
+func (c *Client) FeedStreamsContext(ctx context.Context, resource string) (*Streams, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/streams")
+	if err != nil {
+		return nil, simpleErrorMessage(err, statusCode)
+	}
+	if statusCode == 200 {
+		data, err := parseStreams(result.body)
+		if err != nil {
+			return nil, simpleErrorMessage(err, statusCode)
+		}
+		return data, nil
+	}
+	return nil, generateErrorMessage(result, statusCode)
+}
+
+// DeleteFeedStream deletes a feed stream along with its values
+//
+//		err := client.DeleteFeedStream("/feeds/1234", "temperature")
+func (c *Client) DeleteFeedStream(resource string, name string) *ErrorMessage {
+	return c.DeleteFeedStreamContext(context.Background(), resource, name)
+}
+
+// DeleteFeedStreamContext is like DeleteFeedStream but uses ctx for the request
+func (c *Client) DeleteFeedStreamContext(ctx context.Context, resource string, name string) *ErrorMessage {
+	result, statusCode, err := c.delete(ctx, c.APIBase+resource+"/streams", name)
+	if err != nil {
+		return simpleErrorMessage(err, statusCode)
+	}
+	if statusCode == 204 {
+		return nil
+	}
+	return generateErrorMessage(result, statusCode)
+}
+
 // FeedStream list a feed stream
 //
 //		stream, err := client.FeedStream("/feeds/1234", "temperature")
 func (c *Client) FeedStream(resource string, name string) (*Stream, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/streams/" + name)
+	return c.FeedStreamContext(context.Background(), resource, name)
+}
+
+// FeedStreamContext is like FeedStream but uses ctx for the request
+func (c *Client) FeedStreamContext(ctx context.Context, resource string, name string) (*Stream, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/streams/"+name)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseStream(result)
+		data, err := parseStream(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -198,12 +564,43 @@ func (c *Client) FeedStream(resource string, name string) (*Stream, *ErrorMessag
 // 		unit["symbol"] = "C"
 // 		streamData["unit"] = unit
 // 		err := client.UpdateFeedStream("/feeds/1234", "temperature", streamData)
+//
+// Deprecated: use UpdateFeedStreamWithParams, which checks the data before sending it.
 func (c *Client) UpdateFeedStream(resource string, name string, updateData map[string]interface{}) *ErrorMessage {
+	return c.UpdateFeedStreamContext(context.Background(), resource, name, updateData)
+}
+
+// UpdateFeedStreamContext is like UpdateFeedStream but uses ctx for the request
+//
+// Deprecated: use UpdateFeedStreamWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateFeedStreamContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *ErrorMessage {
+	return c.updateFeedStream(ctx, resource, name, updateData)
+}
+
+// UpdateFeedStreamWithParams creates or updates a feed stream, checking the params first
+//
+//		err := client.UpdateFeedStreamWithParams("/feeds/1234", "temperature", &StreamParams{
+//			Unit: &Unit{Label: "celcius", Symbol: "C"},
+//		})
+func (c *Client) UpdateFeedStreamWithParams(resource string, name string, params *StreamParams) *ErrorMessage {
+	return c.UpdateFeedStreamWithParamsContext(context.Background(), resource, name, params)
+}
+
+// UpdateFeedStreamWithParamsContext is like UpdateFeedStreamWithParams but uses ctx for the request
+func (c *Client) UpdateFeedStreamWithParamsContext(ctx context.Context, resource string, name string, params *StreamParams) *ErrorMessage {
+	if errs := params.validate(name); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateFeedStream(ctx, resource, name, params)
+}
+
+// Sends the data of UpdateFeedStream, given either as a map or as params
+func (c *Client) updateFeedStream(ctx context.Context, resource string, name string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, putErr := put(c.APIBase+resource+"/streams/"+name, data)
+	result, statusCode, putErr := c.put(ctx, c.APIBase+resource+"/streams/"+name, data)
 	if putErr != nil {
 		return simpleErrorMessage(putErr, 0)
 	}
@@ -218,12 +615,35 @@ func (c *Client) UpdateFeedStream(resource string, name string, updateData map[s
 //
 //		values, err := client.FeedStreamValues("/feeds/1234", "temperature")
 func (c *Client) FeedStreamValues(resource string, name string) (*Values, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/streams/" + name + "/values")
+	return c.FeedStreamValuesContext(context.Background(), resource, name)
+}
+
+// FeedStreamValuesContext is like FeedStreamValues but uses ctx for the request
+func (c *Client) FeedStreamValuesContext(ctx context.Context, resource string, name string) (*Values, *ErrorMessage) {
+	return c.QueryFeedStreamValuesContext(ctx, resource, name, nil)
+}
+
+// QueryFeedStreamValues lists the values of a feed stream within a time window
+//
+//		values, err := client.QueryFeedStreamValues("/feeds/1234", "temperature", &ValuesQuery{
+//			Start: time.Now().Add(-24 * time.Hour),
+//			Limit: 500,
+//		})
+func (c *Client) QueryFeedStreamValues(resource string, name string, query *ValuesQuery) (*Values, *ErrorMessage) {
+	return c.QueryFeedStreamValuesContext(context.Background(), resource, name, query)
+}
+
+// QueryFeedStreamValuesContext is like QueryFeedStreamValues but uses ctx for the request
+func (c *Client) QueryFeedStreamValuesContext(ctx context.Context, resource string, name string, query *ValuesQuery) (*Values, *ErrorMessage) {
+	if errs := query.validate(); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/streams/"+name+"/values"+query.query())
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseValues(result)
+		data, err := parseValues(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -232,22 +652,108 @@ func (c *Client) FeedStreamValues(resource string, name string) (*Values, *Error
 	return nil, generateErrorMessage(result, statusCode)
 }
 
+// WalkFeedStreamValues calls fn for every value of a feed stream within the
+// window of the query, newest first. The window is walked backwards in
+// requests of query.Limit values, moving its end to the oldest value received
+// until the start of the window is reached, whatever the order of the query.
+// The walk stops early if fn returns an error, which is then returned. It also
+// fails if more values share a timestamp than query.Limit, as the values past
+// the limit could not be reached.
+//
+//		err := client.WalkFeedStreamValues(ctx, "/feeds/1234", "temperature", &ValuesQuery{Start: start}, func(value Value) error {
+//			log.Println(value.At, value.Value)
+//			return nil
+//		})
+func (c *Client) WalkFeedStreamValues(ctx context.Context, resource string, name string, query *ValuesQuery, fn func(Value) error) *ErrorMessage {
+	window := ValuesQuery{Limit: defaultValuesLimit}
+	if query != nil {
+		window = *query
+		if window.Limit <= 0 {
+			window.Limit = defaultValuesLimit
+		}
+	}
+	// The end of the window is moved backwards, so values must come newest first
+	window.Order = "desc"
+
+	// Number of values at the end of the window already passed to fn, by value
+	var boundary time.Time
+	visited := make(map[string]int)
+	for {
+		values, errorMessage := c.QueryFeedStreamValuesContext(ctx, resource, name, &window)
+		if errorMessage != nil {
+			return errorMessage
+		}
+		if len(values.Values) == 0 {
+			return nil
+		}
+
+		oldest := values.Values[0].At.Time
+		passed := 0
+		for _, value := range values.Values {
+			if value.At.Before(oldest) {
+				oldest = value.At.Time
+			}
+			if value.At.Equal(boundary) && visited[value.Value.String()] > 0 {
+				visited[value.Value.String()]--
+				continue
+			}
+			if err := fn(value); err != nil {
+				return simpleErrorMessage(err, 0)
+			}
+			passed++
+		}
+		if len(values.Values) < window.Limit || (!window.Start.IsZero() && !oldest.After(window.Start)) {
+			return nil
+		}
+		if passed == 0 {
+			// Every value received shares a timestamp and was already passed
+			// to fn. Unless there are more at that timestamp than fit in a
+			// request, which could not be reached, move past them.
+			at := ValuesQuery{Start: oldest, End: oldest, Limit: window.Limit + 1, Order: "desc"}
+			values, errorMessage := c.QueryFeedStreamValuesContext(ctx, resource, name, &at)
+			if errorMessage != nil {
+				return errorMessage
+			}
+			if len(values.Values) > window.Limit {
+				err := fmt.Errorf("more than %d values of stream %s share the timestamp %s, raise the limit of the query", window.Limit, name, oldest.UTC().Format(time.RFC3339Nano))
+				return simpleErrorMessage(err, 0)
+			}
+			window.End = oldest.Add(-time.Millisecond)
+			boundary, visited = time.Time{}, make(map[string]int)
+			continue
+		}
+
+		// Every value of the request at its oldest timestamp has been passed to
+		// fn, and will be received again as the window now ends there
+		boundary, visited = oldest, make(map[string]int)
+		for _, value := range values.Values {
+			if value.At.Equal(boundary) {
+				visited[value.Value.String()]++
+			}
+		}
+		window.End = oldest
+	}
+}
+
 // UpdateFeedStreamValues update feeds stream values
 //
 // 		values := make(map[string]interface{})
 // 		values["values"] = []*m2x.Value{
-// 			{"2013-09-09T19:15:00Z", "32"},
-// 			{"2013-09-09T19:16:00Z", "28 "},
-// 			{"2013-09-09T19:17:00Z", "25"},
-// 			{"2013-09-09T19:17:00Z", "40"},
+// 			{At: m2x.NewTimestamp(time.Now().Add(-time.Minute)), Value: m2x.Float64Value(32)},
+// 			{At: m2x.NewTimestamp(time.Now()), Value: m2x.Float64Value(28.5)},
 // 		}
 // 		err := client.UpdateFeedStreamValues("/feeds/1234", "temperature", values)
 func (c *Client) UpdateFeedStreamValues(resource string, name string, updateData map[string]interface{}) *ErrorMessage {
+	return c.UpdateFeedStreamValuesContext(context.Background(), resource, name, updateData)
+}
+
+// UpdateFeedStreamValuesContext is like UpdateFeedStreamValues but uses ctx for the request
+func (c *Client) UpdateFeedStreamValuesContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, putErr := post(c.APIBase+resource+"/streams/"+name+"/values", data)
+	result, statusCode, putErr := c.post(ctx, c.APIBase+resource+"/streams/"+name+"/values", data)
 	if putErr != nil {
 		return simpleErrorMessage(putErr, statusCode)
 	}
@@ -257,16 +763,50 @@ func (c *Client) UpdateFeedStreamValues(resource string, name string, updateData
 	return generateErrorMessage(result, statusCode)
 }
 
+// DeleteFeedStreamValues deletes the values of a feed stream within a range
+//
+//		err := client.DeleteFeedStreamValues("/feeds/1234", "temperature", &m2x.ValuesRange{
+//			From: time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC),
+//			End:  time.Date(2014, 3, 2, 0, 0, 0, 0, time.UTC),
+//		})
+func (c *Client) DeleteFeedStreamValues(resource string, name string, valuesRange *ValuesRange) *ErrorMessage {
+	return c.DeleteFeedStreamValuesContext(context.Background(), resource, name, valuesRange)
+}
+
+// DeleteFeedStreamValuesContext is like DeleteFeedStreamValues but uses ctx for the request
+func (c *Client) DeleteFeedStreamValuesContext(ctx context.Context, resource string, name string, valuesRange *ValuesRange) *ErrorMessage {
+	if errs := valuesRange.validate(); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	data, err := json.Marshal(valuesRange.body())
+	if err != nil {
+		return simpleErrorMessage(err, 0)
+	}
+	result, statusCode, deleteErr := c.deleteData(ctx, c.APIBase+resource+"/streams/"+name+"/values", data)
+	if deleteErr != nil {
+		return simpleErrorMessage(deleteErr, statusCode)
+	}
+	if statusCode == 204 || statusCode == 202 {
+		return nil
+	}
+	return generateErrorMessage(result, statusCode)
+}
+
 // RequestLog requests a log
 //
 //		requests, err := RequestLog("/feeds/1234")
 func (c *Client) RequestLog(resource string) (*Requests, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/log")
+	return c.RequestLogContext(context.Background(), resource)
+}
+
+// RequestLogContext is like RequestLog but uses ctx for the request
+func (c *Client) RequestLogContext(ctx context.Context, resource string) (*Requests, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/log")
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseRequests(result)
+		data, err := parseRequests(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -305,6 +845,16 @@ func parseStream(data []byte) (*Stream, error) {
 	return stream, nil
 }
 
+// Parses the Streams JSON
+func parseStreams(data []byte) (*Streams, error) {
+	streams := &Streams{}
+	err := json.Unmarshal(data, &streams)
+	if err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
 // Parses the Values JSON
 func parseValues(data []byte) (*Values, error) {
 	values := &Values{}
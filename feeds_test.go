@@ -4,7 +4,13 @@
 package m2x
 
 import (
-	"os"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -63,7 +69,7 @@ func TestParseFeeds(t *testing.T) {
 		t.Errorf("Location name did not parse properly")
 	}
 
-	if result.Feeds[1].Location.Waypoints[0].Timestamp != "2013-09-10T19:15:00Z" {
+	if !result.Feeds[1].Location.Waypoints[0].Timestamp.Equal(time.Date(2013, 9, 10, 19, 15, 0, 0, time.UTC)) {
 		t.Errorf("Waypoints timestamp did not parse properly")
 	}
 }
@@ -148,7 +154,7 @@ func TestParseFeed(t *testing.T) {
 }
 
 func TestListFeeds(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	result, err := client.Feeds()
 	if err != nil {
 		t.Errorf("Listing the feeds did not work properly")
@@ -159,7 +165,7 @@ func TestListFeeds(t *testing.T) {
 }
 
 func TestListBadFeedId(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	_, errorMessage := client.Feed("/feeds/1234")
 	if errorMessage.StatusCode != 404 || errorMessage.Message != "The specified feed does not exist" {
 		t.Errorf("We did not get the proper error message or code back")
@@ -167,7 +173,7 @@ func TestListBadFeedId(t *testing.T) {
 }
 
 func TestFeedLocation(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 
 	// Create a new blueprint
 	blueprint := make(map[string]string)
@@ -200,7 +206,7 @@ func TestFeedLocation(t *testing.T) {
 }
 
 func TestFeedStream(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 
 	// Create a new blueprint
 	blueprint := make(map[string]string)
@@ -233,9 +239,9 @@ func TestFeedStream(t *testing.T) {
 	// Update values on a stream
 	values := make(map[string]interface{})
 	values["values"] = []*Value{
-		{"2013-09-09T19:15:00Z", "32"},
-		{"2013-09-09T19:16:00Z", "28 "},
-		{"2013-09-09T19:17:00Z", "25"},
+		{At: NewTimestamp(time.Date(2013, 9, 9, 19, 15, 0, 0, time.UTC)), Value: Int64Value(32)},
+		{At: NewTimestamp(time.Date(2013, 9, 9, 19, 16, 0, 0, time.UTC)), Value: StringValue("28 ")},
+		{At: NewTimestamp(time.Date(2013, 9, 9, 19, 17, 0, 0, time.UTC)), Value: Float64Value(25)},
 	}
 
 	errorMessage = client.UpdateFeedStreamValues(result.Feed, "temperature", values)
@@ -265,3 +271,314 @@ func TestFeedStream(t *testing.T) {
 		t.Errorf("Did not delete feed properly")
 	}
 }
+
+func TestUpdateFeedLocationWithParams(t *testing.T) {
+	server, client, last := newRecordingServer(202, ``)
+	defer server.Close()
+
+	errorMessage := client.UpdateFeedLocationWithParams("/feeds/1234", &LocationParams{Latitude: "137.38", Longitude: "west"})
+	if errorMessage == nil || len(errorMessage.Errors["latitude"]) != 1 || len(errorMessage.Errors["longitude"]) != 1 {
+		t.Errorf("Invalid coordinates were not rejected: %v", errorMessage)
+	}
+
+	errorMessage = client.UpdateFeedLocationWithParams("/feeds/1234", &LocationParams{Name: "Storage Room", Latitude: "-37.978", Longitude: "-57.547"})
+	if errorMessage != nil {
+		t.Errorf("Did not update the location properly: %v", errorMessage)
+	}
+	if _, path, _, body := last.get(); path != "/feeds/1234/location" || body != `{"name":"Storage Room","latitude":"-37.978","longitude":"-57.547"}` {
+		t.Errorf("Location params were not sent properly: %s %s", path, body)
+	}
+}
+
+func TestUpdateFeedStreamWithParams(t *testing.T) {
+	server, client, last := newRecordingServer(201, ``)
+	defer server.Close()
+
+	errorMessage := client.UpdateFeedStreamWithParams("/feeds/1234", "", &StreamParams{Unit: &Unit{Symbol: "C"}})
+	if !IsValidation(errorMessage) || len(errorMessage.Errors["name"]) != 1 || len(errorMessage.Errors["unit.label"]) != 1 {
+		t.Errorf("A stream without a name or a unit without a label should not have been sent: %v", errorMessage)
+	}
+
+	errorMessage = client.UpdateFeedStreamWithParams("/feeds/1234", "temperature", &StreamParams{Unit: &Unit{Label: "celsius", Symbol: "C"}})
+	if errorMessage != nil {
+		t.Errorf("Did not update the stream properly: %v", errorMessage)
+	}
+	if _, path, _, body := last.get(); path != "/feeds/1234/streams/temperature" || body != `{"unit":{"label":"celsius","symbol":"C"}}` {
+		t.Errorf("Stream params were not sent properly: %s %s", path, body)
+	}
+	if errorMessage := client.UpdateFeedStreamWithParams("/feeds/1234", "humidity", nil); errorMessage != nil {
+		t.Errorf("A stream without params should be accepted: %v", errorMessage)
+	}
+}
+
+func TestSearchFeeds(t *testing.T) {
+	server, client, last := newRecordingServer(200, `{"feeds":[{"id":"1234"}],"total":1,"pages":1,"limit":10,"current_page":1}`)
+	defer server.Close()
+
+	query := &FeedQuery{
+		ListOptions:  ListOptions{Limit: 10},
+		Type:         "batch",
+		Visibility:   "private",
+		Tags:         []string{"warehouse", "spain"},
+		Latitude:     "37.383055",
+		Longitude:    "-5.996392",
+		Distance:     "5",
+		DistanceUnit: "km",
+	}
+	feeds, errorMessage := client.SearchFeeds(query)
+	if errorMessage != nil || feeds.Feeds[0].ID != "1234" {
+		t.Errorf("Did not search the feeds properly: %v", errorMessage)
+	}
+	expected := "distance=5&distance_unit=km&latitude=37.383055&limit=10&longitude=-5.996392&tags=warehouse%2Cspain&type=batch&visibility=private"
+	if _, path, rawQuery, _ := last.get(); path != "/feeds" || rawQuery != expected {
+		t.Errorf("Query was not encoded properly: %s", rawQuery)
+	}
+
+	_, errorMessage = client.SearchFeeds(&FeedQuery{Latitude: "37.383055", DistanceUnit: "leagues"})
+	if !IsValidation(errorMessage) || len(errorMessage.FieldErrors()) != 3 {
+		t.Errorf("An incomplete geo query should not have been sent: %v", errorMessage.FieldErrors())
+	}
+
+	count := 0
+	results := client.IterateFeedSearch(context.Background(), &FeedQuery{Query: "sensor"})
+	for results.Next() {
+		count++
+	}
+	if _, _, rawQuery, _ := last.get(); count != 1 || rawQuery != "page=1&q=sensor" {
+		t.Errorf("Did not iterate over the search results properly: %s", rawQuery)
+	}
+}
+
+// Serves the values of a stream newest first, or oldest first if asked, within
+// the requested window
+func newValuesServer(values []Value) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := ParseTimestamp(r.URL.Query().Get("start"))
+		end, _ := ParseTimestamp(r.URL.Query().Get("end"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 100
+		}
+		window := []Value{}
+		for i := range values {
+			if r.URL.Query().Get("order") != "asc" {
+				i = len(values) - 1 - i
+			}
+			at := values[i].At
+			if len(window) < limit && (start.IsZero() || !at.Before(start.Time)) && (end.IsZero() || !at.After(end.Time)) {
+				window = append(window, values[i])
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"limit": limit, "values": window})
+	}))
+	return server, &requests
+}
+
+func TestWalkFeedStreamValues(t *testing.T) {
+	first := time.Date(2013, 9, 9, 19, 0, 0, 0, time.UTC)
+	var values []Value
+	for i := 0; i < 250; i++ {
+		values = append(values, Value{At: NewTimestamp(first.Add(time.Duration(i) * time.Minute)), Value: Int64Value(int64(i))})
+		if i == 149 {
+			// A second value sharing the timestamp of the end of a window
+			values = append(values, Value{At: NewTimestamp(first.Add(time.Duration(i) * time.Minute)), Value: Int64Value(1000)})
+		}
+	}
+	server, requests := newValuesServer(values)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	var walked []int64
+	errorMessage := client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", &ValuesQuery{Limit: 50}, func(value Value) error {
+		i, _ := value.Value.Int64()
+		walked = append(walked, i)
+		return nil
+	})
+	if errorMessage != nil || len(walked) != 251 {
+		t.Fatalf("Did not walk all the values: %d %v", len(walked), errorMessage)
+	}
+	if walked[0] != 249 || walked[250] != 0 {
+		t.Errorf("Values were not walked newest first: %d %d", walked[0], walked[250])
+	}
+
+	*requests = 0
+	walked = nil
+	query := &ValuesQuery{Start: first.Add(200 * time.Minute), End: first.Add(229 * time.Minute), Limit: 10}
+	client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", query, func(value Value) error {
+		i, _ := value.Value.Int64()
+		walked = append(walked, i)
+		return nil
+	})
+	if len(walked) != 30 || walked[0] != 229 || walked[29] != 200 || *requests != 4 {
+		t.Errorf("Did not walk the window properly: %v in %d requests", walked, *requests)
+	}
+
+	walked = nil
+	client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", &ValuesQuery{Limit: 50, Order: "asc"}, func(value Value) error {
+		i, _ := value.Value.Int64()
+		walked = append(walked, i)
+		return nil
+	})
+	if len(walked) != 251 || walked[0] != 249 {
+		t.Errorf("Values should be walked newest first whatever the order of the query: %d", len(walked))
+	}
+
+	stop := errors.New("enough")
+	errorMessage = client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", nil, func(value Value) error {
+		return stop
+	})
+	if !errors.Is(errorMessage, stop) {
+		t.Errorf("The error of the callback was not returned: %v", errorMessage)
+	}
+}
+
+func TestWalkFeedStreamValuesSharingTimestamps(t *testing.T) {
+	first := time.Date(2013, 9, 9, 19, 0, 0, 0, time.UTC)
+	at := func(minutes int, value int64) Value {
+		return Value{At: NewTimestamp(first.Add(time.Duration(minutes) * time.Minute)), Value: Int64Value(value)}
+	}
+	// Identical readings at the timestamp where the first request ends
+	server, _ := newValuesServer([]Value{at(0, 1), at(1, 5), at(1, 5), at(2, 3)})
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	var walked []string
+	errorMessage := client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", &ValuesQuery{Limit: 2}, func(value Value) error {
+		walked = append(walked, value.Value.String())
+		return nil
+	})
+	if errorMessage != nil || strings.Join(walked, ",") != "3,5,5,1" {
+		t.Errorf("Did not walk every value: %v %v", walked, errorMessage)
+	}
+
+	server, _ = newValuesServer([]Value{at(0, 1), at(1, 5), at(1, 6), at(1, 7), at(2, 3)})
+	defer server.Close()
+	client.APIBase = server.URL
+	walked = nil
+	errorMessage = client.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", &ValuesQuery{Limit: 2}, func(value Value) error {
+		walked = append(walked, value.Value.String())
+		return nil
+	})
+	if errorMessage == nil || !strings.Contains(errorMessage.Message, "raise the limit") || len(walked) != 3 {
+		t.Errorf("More values sharing a timestamp than the limit should fail: %v %v", walked, errorMessage)
+	}
+}
+
+func TestQueryFeedStreamValues(t *testing.T) {
+	server, client, last := newRecordingServer(200, `{"limit":10,"values":[]}`)
+	defer server.Close()
+
+	start := time.Date(2013, 9, 9, 19, 0, 0, 0, time.UTC)
+	_, errorMessage := client.QueryFeedStreamValues("/feeds/1234", "temperature", &ValuesQuery{Start: start, End: start.Add(time.Hour), Limit: 10, Order: "asc"})
+	if errorMessage != nil {
+		t.Errorf("Did not query the values properly: %v", errorMessage)
+	}
+	if _, _, rawQuery, _ := last.get(); rawQuery != "end=2013-09-09T20%3A00%3A00Z&limit=10&order=asc&start=2013-09-09T19%3A00%3A00Z" {
+		t.Errorf("Query was not encoded properly: %s", rawQuery)
+	}
+
+	_, errorMessage = client.QueryFeedStreamValues("/feeds/1234", "temperature", &ValuesQuery{Start: start, End: start.Add(-time.Hour)})
+	if !IsValidation(errorMessage) {
+		t.Errorf("A window ending before its start should not have been sent")
+	}
+}
+
+func TestParseStreams(t *testing.T) {
+	data := `
+	{ "streams": [
+    { "name": "temperature",
+      "value": "32",
+      "unit": { "label": "celcius", "symbol": "C" },
+      "url": "/feeds/a4f919d931c265ddd7b76649eac22f7e/streams/temperature" },
+    { "name": "humidity",
+      "value": 80,
+      "unit": { "label": "percent", "symbol": "%" },
+      "url": "/feeds/a4f919d931c265ddd7b76649eac22f7e/streams/humidity" } ] }`
+
+	result, _ := parseStreams([]byte(data))
+	if len(result.Streams) != 2 || result.Streams[0].Name != "temperature" || result.Streams[1].Unit.Symbol != "%" {
+		t.Errorf("Streams did not parse properly")
+	}
+}
+
+func TestFeedStreamsAndDelete(t *testing.T) {
+	server, client, last := newRecordingServer(200, `{"streams":[{"name":"temperature"},{"name":"humidity"}]}`)
+	defer server.Close()
+
+	streams, errorMessage := client.FeedStreams("/feeds/1234")
+	if errorMessage != nil || len(streams.Streams) != 2 {
+		t.Errorf("Did not list the streams properly: %v", errorMessage)
+	}
+	if _, path, _, _ := last.get(); path != "/feeds/1234/streams" {
+		t.Errorf("Streams were not requested properly: %s", path)
+	}
+
+	server, client, last = newRecordingServer(204, ``)
+	defer server.Close()
+	if errorMessage := client.DeleteFeedStream("/feeds/1234", "humidity"); errorMessage != nil {
+		t.Errorf("Did not delete the stream properly: %v", errorMessage)
+	}
+	if method, path, _, _ := last.get(); method != "DELETE" || path != "/feeds/1234/streams/humidity" {
+		t.Errorf("Stream was not deleted properly: %s %s", method, path)
+	}
+
+	server, client, _ = newRecordingServer(404, `{"message":"The specified stream does not exist"}`)
+	defer server.Close()
+	if errorMessage := client.DeleteFeedStream("/feeds/1234", "pressure"); !IsNotFound(errorMessage) {
+		t.Errorf("Deleting a missing stream should fail: %v", errorMessage)
+	}
+}
+
+func TestDeleteFeedStreamValues(t *testing.T) {
+	server, client, last := newRecordingServer(204, ``)
+	defer server.Close()
+
+	from := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	errorMessage := client.DeleteFeedStreamValues("/feeds/1234", "temperature", &ValuesRange{From: from, End: from.Add(24 * time.Hour)})
+	if errorMessage != nil {
+		t.Errorf("Did not delete the values properly: %v", errorMessage)
+	}
+	if method, path, _, body := last.get(); method != "DELETE" || path != "/feeds/1234/streams/temperature/values" || body != `{"end":"2014-03-02T00:00:00Z","from":"2014-03-01T00:00:00Z"}` {
+		t.Errorf("Values were not deleted properly: %s %s %s", method, path, body)
+	}
+
+	errorMessage = client.DeleteFeedStreamValues("/feeds/1234", "temperature", &ValuesRange{From: from, ConfirmOpenEnd: true})
+	if errorMessage != nil {
+		t.Errorf("A confirmed open range was rejected: %v", errorMessage)
+	}
+	if _, _, _, body := last.get(); body != `{"end":"9999-12-31T23:59:59Z","from":"2014-03-01T00:00:00Z"}` {
+		t.Errorf("The open end of the range was not filled in: %s", body)
+	}
+}
+
+func TestDeleteFeedStreamValuesGuard(t *testing.T) {
+	server, client, last := newRecordingServer(204, ``)
+	defer server.Close()
+
+	from := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, valuesRange := range []*ValuesRange{nil, {}, {From: from}, {End: from}, {From: from, End: from.Add(-time.Hour)}} {
+		if errorMessage := client.DeleteFeedStreamValues("/feeds/1234", "temperature", valuesRange); !IsValidation(errorMessage) {
+			t.Errorf("An unconfirmed or invalid range should not have been sent: %+v", valuesRange)
+		}
+	}
+	if errs := (&ValuesRange{}).validate(); len(errs["from"]) != 1 || len(errs["end"]) != 1 {
+		t.Errorf("Both open sides should be reported: %v", errs)
+	}
+	if method, _, _, _ := last.get(); method != "" {
+		t.Errorf("No request should have been sent")
+	}
+}
+
+func TestFeedMalformedResponse(t *testing.T) {
+	server, client, _ := newRecordingServer(200, `{"id": "1234", "created": "yesterday"}`)
+	defer server.Close()
+
+	feed, errorMessage := client.Feed("/feeds/1234")
+	if feed != nil || errorMessage == nil || errorMessage.Err == nil {
+		t.Errorf("A malformed response should have been reported: %v", errorMessage)
+	}
+}
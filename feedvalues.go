@@ -0,0 +1,184 @@
+// Copyright (c) 2014 Jason Goecke
+// feedvalues.go
+
+package m2x
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// DefaultMaxValuesPerRequest is the number of values UpdateFeedValues sends
+	// in a single request when FeedValues.MaxPerRequest is not set
+	DefaultMaxValuesPerRequest = 1000
+	// DefaultMaxBytesPerRequest is the size of the largest body UpdateFeedValues
+	// sends in a single request when FeedValues.MaxBytesPerRequest is not set
+	DefaultMaxBytesPerRequest = 1 << 20
+)
+
+// The size of a request body without any value: {"values":{}}
+const emptyValuesBodySize = 13
+
+// FeedValues collects the values of several streams of a feed so they can be
+// posted together with UpdateFeedValues. Values are grouped by stream, keeping
+// the order in which they were added. A FeedValues is not safe for concurrent
+// use.
+//
+//		values := m2x.NewFeedValues().
+//			Add("temperature", time.Now(), m2x.Float64Value(21.5)).
+//			Add("humidity", time.Now(), m2x.Int64Value(40))
+//		err := client.UpdateFeedValues("/feeds/1234", values)
+type FeedValues struct {
+	// MaxPerRequest is the largest number of values sent in one request.
+	// Larger collections are split over several requests.
+	MaxPerRequest int
+	// MaxBytesPerRequest is the size of the largest body sent in one request,
+	// in bytes. Collections encoding to larger bodies are split over several
+	// requests. A single value larger than this is sent in a request of its own.
+	MaxBytesPerRequest int
+
+	streams map[string][]Value
+	names   []string
+	count   int
+}
+
+// NewFeedValues creates an empty collection of feed values
+func NewFeedValues() *FeedValues {
+	return &FeedValues{streams: make(map[string][]Value)}
+}
+
+// Add adds a value of a stream taken at the given time
+func (v *FeedValues) Add(stream string, at time.Time, value StreamValue) *FeedValues {
+	return v.AddValues(stream, Value{At: NewTimestamp(at), Value: value})
+}
+
+// AddValues adds values of a stream
+func (v *FeedValues) AddValues(stream string, values ...Value) *FeedValues {
+	if v.streams == nil {
+		v.streams = make(map[string][]Value)
+	}
+	if _, ok := v.streams[stream]; !ok {
+		v.names = append(v.names, stream)
+	}
+	v.streams[stream] = append(v.streams[stream], values...)
+	v.count += len(values)
+	return v
+}
+
+// Len returns the number of values in the collection
+func (v *FeedValues) Len() int {
+	if v == nil {
+		return 0
+	}
+	return v.count
+}
+
+// Streams returns the names of the streams with values, in the order they were
+// first added
+func (v *FeedValues) Streams() []string {
+	if v == nil {
+		return nil
+	}
+	return append([]string(nil), v.names...)
+}
+
+// Checks the stream names of the collection
+func (v *FeedValues) validate() Error {
+	errs := Error{}
+	for _, name := range v.Streams() {
+		if name == "" {
+			errs.add("stream", "can't be blank")
+		}
+	}
+	return errs
+}
+
+// Splits the collection into request bodies of at most MaxPerRequest values
+// encoding to at most MaxBytesPerRequest bytes. A stream with more values than
+// fit in the current body carries on in the next one.
+func (v *FeedValues) split() ([]map[string]map[string][]Value, error) {
+	max := v.MaxPerRequest
+	if max <= 0 {
+		max = DefaultMaxValuesPerRequest
+	}
+	maxBytes := v.MaxBytesPerRequest
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytesPerRequest
+	}
+	var bodies []map[string]map[string][]Value
+	var current map[string][]Value
+	room, size := 0, 0
+	for _, name := range v.names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		// The stream adds its key, a colon, brackets and a comma to a body
+		streamSize := len(key) + 4
+		for _, value := range v.streams[name] {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			grow := len(encoded) + 1
+			if _, ok := current[name]; !ok {
+				grow += streamSize
+			}
+			if room == 0 || (size+grow > maxBytes && size > emptyValuesBodySize) {
+				current = make(map[string][]Value)
+				bodies = append(bodies, map[string]map[string][]Value{"values": current})
+				room, size = max, emptyValuesBodySize
+				grow = len(encoded) + 1 + streamSize
+			}
+			current[name] = append(current[name], value)
+			room--
+			size += grow
+		}
+	}
+	return bodies, nil
+}
+
+// UpdateFeedValues posts the values of several streams of a feed. Collections
+// with more than values.MaxPerRequest values, or encoding to more than
+// values.MaxBytesPerRequest bytes, are sent in several requests, stopping at
+// the first one that fails; the values of the requests sent before it have
+// already been accepted. An empty collection sends nothing.
+//
+//		values := m2x.NewFeedValues()
+//		for _, reading := range readings {
+//			values.Add(reading.Sensor, reading.At, m2x.Float64Value(reading.Value))
+//		}
+//		err := client.UpdateFeedValues("/feeds/1234", values)
+func (c *Client) UpdateFeedValues(resource string, values *FeedValues) *ErrorMessage {
+	return c.UpdateFeedValuesContext(context.Background(), resource, values)
+}
+
+// UpdateFeedValuesContext is like UpdateFeedValues but uses ctx for the requests
+func (c *Client) UpdateFeedValuesContext(ctx context.Context, resource string, values *FeedValues) *ErrorMessage {
+	if errs := values.validate(); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	if values.Len() == 0 {
+		return nil
+	}
+	bodies, err := values.split()
+	if err != nil {
+		return simpleErrorMessage(err, 0)
+	}
+	for _, body := range bodies {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return simpleErrorMessage(err, 0)
+		}
+		result, statusCode, postErr := c.post(ctx, c.APIBase+resource, data)
+		if postErr != nil {
+			return simpleErrorMessage(postErr, statusCode)
+		}
+		if statusCode != 204 && statusCode != 202 {
+			return generateErrorMessage(result, statusCode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2014 Jason Goecke
+// feedvalues_test.go
+
+package m2x
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedValuesSplit(t *testing.T) {
+	at := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	values := NewFeedValues()
+	values.MaxPerRequest = 3
+	for i := 0; i < 4; i++ {
+		values.Add("temperature", at.Add(time.Duration(i)*time.Minute), Int64Value(int64(20+i)))
+	}
+	values.Add("humidity", at, Int64Value(40)).Add("humidity", at.Add(time.Minute), Int64Value(41))
+
+	if values.Len() != 6 || len(values.Streams()) != 2 || values.Streams()[0] != "temperature" {
+		t.Errorf("Values were not grouped by stream properly")
+	}
+	bodies, err := values.split()
+	if err != nil || len(bodies) != 2 {
+		t.Fatalf("Values should have been split in two requests, got %d", len(bodies))
+	}
+	if len(bodies[0]["values"]["temperature"]) != 3 || len(bodies[1]["values"]["temperature"]) != 1 || len(bodies[1]["values"]["humidity"]) != 2 {
+		t.Errorf("Values were not split properly: %v", bodies)
+	}
+	if bodies[1]["values"]["temperature"][0].Value.String() != "23" {
+		t.Errorf("Values of a stream should keep their order across requests")
+	}
+}
+
+func TestFeedValuesSplitLargeValues(t *testing.T) {
+	at := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	values := NewFeedValues()
+	for i := 0; i < 5; i++ {
+		values.Add("log", at.Add(time.Duration(i)*time.Minute), StringValue(strings.Repeat(strconv.Itoa(i), 400<<10)))
+	}
+	values.Add("temperature", at, Int64Value(20))
+
+	bodies, err := values.split()
+	if err != nil || len(bodies) != 3 {
+		t.Fatalf("Values should have been split in three requests, got %d: %v", len(bodies), err)
+	}
+	var walked []string
+	for _, body := range bodies {
+		data, _ := json.Marshal(body)
+		if len(data) > DefaultMaxBytesPerRequest {
+			t.Errorf("A body of %d bytes is larger than the maximum", len(data))
+		}
+		for _, value := range body["values"]["log"] {
+			walked = append(walked, value.Value.String()[:1])
+		}
+	}
+	if strings.Join(walked, "") != "01234" || len(bodies[2]["values"]["temperature"]) != 1 {
+		t.Errorf("Values were not split properly: %v", walked)
+	}
+
+	values = NewFeedValues()
+	values.MaxBytesPerRequest = 64
+	values.Add("log", at, StringValue(strings.Repeat("x", 100))).Add("log", at, StringValue("y"))
+	if bodies, err := values.split(); err != nil || len(bodies) != 2 || len(bodies[0]["values"]["log"]) != 1 {
+		t.Errorf("A value larger than the maximum should be sent on its own: %v", err)
+	}
+}
+
+func TestUpdateFeedValues(t *testing.T) {
+	var bodies []map[string]map[string][]Value
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body := map[string]map[string][]Value{}
+		json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if len(bodies) == 3 {
+			w.WriteHeader(422)
+			w.Write([]byte(`{"message":"Validation Failed","errors":{"value":["is invalid"]}}`))
+			return
+		}
+		w.WriteHeader(202)
+	}))
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	at := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	values := NewFeedValues()
+	values.MaxPerRequest = 2
+	for _, stream := range []string{"temperature", "humidity", "pressure"} {
+		values.Add(stream, at, Int64Value(1)).Add(stream, at.Add(time.Minute), Int64Value(2))
+	}
+	values.Add("wind", at, StringValue("calm"))
+
+	errorMessage := client.UpdateFeedValues("/feeds/1234", values)
+	if !IsValidation(errorMessage) {
+		t.Errorf("The failed request was not reported: %v", errorMessage)
+	}
+	if len(bodies) != 3 || paths[0] != "POST /feeds/1234" || len(bodies[0]["values"]["temperature"]) != 2 {
+		t.Errorf("Values were not posted properly: %v %v", paths, bodies)
+	}
+
+	if errorMessage := client.UpdateFeedValues("/feeds/1234", NewFeedValues()); errorMessage != nil || len(bodies) != 3 {
+		t.Errorf("An empty collection should not send anything")
+	}
+	if errorMessage := client.UpdateFeedValues("/feeds/1234", NewFeedValues().Add("", at, Int64Value(1))); !IsValidation(errorMessage) {
+		t.Errorf("A blank stream name should not have been sent")
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright (c) 2014 Jason Goecke
+// handles.go
+
+package m2x
+
+import (
+	"context"
+	"net/url"
+)
+
+// FeedHandle refers to a feed by its ID and gives access to the operations on
+// it, its streams and its triggers. IDs and names are escaped when building the
+// paths of requests, so they may contain spaces, slashes or any other character.
+//
+//		feed := client.FeedHandle("1234")
+//		stream, err := feed.Stream("temperature").Get()
+type FeedHandle struct {
+	client *Client
+	id     string
+}
+
+// FeedHandle returns a handle on the feed with the given ID
+//
+//		feed := client.FeedHandle("1234")
+func (c *Client) FeedHandle(id string) FeedHandle {
+	return FeedHandle{client: c, id: id}
+}
+
+// ID returns the ID of the feed
+func (h FeedHandle) ID() string {
+	return h.id
+}
+
+// Resource returns the path of the feed, as taken by the methods of Client
+//
+//		resource := client.FeedHandle("1234").Resource() // "/feeds/1234"
+func (h FeedHandle) Resource() string {
+	return "/feeds/" + url.PathEscape(h.id)
+}
+
+// Get returns the feed
+func (h FeedHandle) Get() (*Feed, *ErrorMessage) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses ctx for the request
+func (h FeedHandle) GetContext(ctx context.Context) (*Feed, *ErrorMessage) {
+	return h.client.FeedContext(ctx, h.Resource())
+}
+
+// Location returns the location of the feed
+func (h FeedHandle) Location() (*Location, *ErrorMessage) {
+	return h.LocationContext(context.Background())
+}
+
+// LocationContext is like Location but uses ctx for the request
+func (h FeedHandle) LocationContext(ctx context.Context) (*Location, *ErrorMessage) {
+	return h.client.FeedLocationContext(ctx, h.Resource())
+}
+
+// UpdateLocation updates the location of the feed
+//
+//		err := feed.UpdateLocation(&LocationParams{Latitude: "37.383055", Longitude: "-5.996392"})
+func (h FeedHandle) UpdateLocation(params *LocationParams) *ErrorMessage {
+	return h.UpdateLocationContext(context.Background(), params)
+}
+
+// UpdateLocationContext is like UpdateLocation but uses ctx for the request
+func (h FeedHandle) UpdateLocationContext(ctx context.Context, params *LocationParams) *ErrorMessage {
+	return h.client.UpdateFeedLocationWithParamsContext(ctx, h.Resource(), params)
+}
+
+// Streams lists the streams of the feed
+func (h FeedHandle) Streams() (*Streams, *ErrorMessage) {
+	return h.StreamsContext(context.Background())
+}
+
+// StreamsContext is like Streams but uses ctx for the request
+func (h FeedHandle) StreamsContext(ctx context.Context) (*Streams, *ErrorMessage) {
+	return h.client.FeedStreamsContext(ctx, h.Resource())
+}
+
+// Stream returns a handle on the stream of the feed with the given name
+//
+//		stream := feed.Stream("temperature")
+func (h FeedHandle) Stream(name string) StreamHandle {
+	return StreamHandle{feed: h, name: name}
+}
+
+// UpdateValues posts values for several streams of the feed
+func (h FeedHandle) UpdateValues(values *FeedValues) *ErrorMessage {
+	return h.UpdateValuesContext(context.Background(), values)
+}
+
+// UpdateValuesContext is like UpdateValues but uses ctx for the requests
+func (h FeedHandle) UpdateValuesContext(ctx context.Context, values *FeedValues) *ErrorMessage {
+	return h.client.UpdateFeedValuesContext(ctx, h.Resource(), values)
+}
+
+// Triggers lists the triggers of the feed
+func (h FeedHandle) Triggers() (*Triggers, *ErrorMessage) {
+	return h.TriggersContext(context.Background())
+}
+
+// TriggersContext is like Triggers but uses ctx for the request
+func (h FeedHandle) TriggersContext(ctx context.Context) (*Triggers, *ErrorMessage) {
+	return h.client.TriggersContext(ctx, h.Resource())
+}
+
+// CreateTrigger creates a trigger on a stream of the feed
+//
+//		trigger, err := feed.CreateTrigger(&TriggerParams{
+//			Name:        "Too hot",
+//			Stream:      "temperature",
+//			Condition:   ">",
+//			Value:       "30",
+//			CallbackURL: "http://example.com/hook",
+//		})
+func (h FeedHandle) CreateTrigger(params *TriggerParams) (*Trigger, *ErrorMessage) {
+	return h.CreateTriggerContext(context.Background(), params)
+}
+
+// CreateTriggerContext is like CreateTrigger but uses ctx for the request
+func (h FeedHandle) CreateTriggerContext(ctx context.Context, params *TriggerParams) (*Trigger, *ErrorMessage) {
+	return h.client.CreateTriggerWithParamsContext(ctx, h.Resource(), params)
+}
+
+// Trigger returns a handle on the trigger of the feed with the given ID
+func (h FeedHandle) Trigger(id string) TriggerHandle {
+	return TriggerHandle{feed: h, id: id}
+}
+
+// RequestLog lists the latest requests made on the feed
+func (h FeedHandle) RequestLog() (*Requests, *ErrorMessage) {
+	return h.RequestLogContext(context.Background())
+}
+
+// RequestLogContext is like RequestLog but uses ctx for the request
+func (h FeedHandle) RequestLogContext(ctx context.Context) (*Requests, *ErrorMessage) {
+	return h.client.RequestLogContext(ctx, h.Resource())
+}
+
+// StreamHandle refers to a stream of a feed by its name
+//
+//		stream := client.FeedHandle("1234").Stream("temperature")
+//		values, err := stream.QueryValues(&ValuesQuery{Start: time.Now().Add(-time.Hour)})
+type StreamHandle struct {
+	feed FeedHandle
+	name string
+}
+
+// Feed returns the handle on the feed of the stream
+func (h StreamHandle) Feed() FeedHandle {
+	return h.feed
+}
+
+// Name returns the name of the stream
+func (h StreamHandle) Name() string {
+	return h.name
+}
+
+// Get returns the stream
+func (h StreamHandle) Get() (*Stream, *ErrorMessage) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses ctx for the request
+func (h StreamHandle) GetContext(ctx context.Context) (*Stream, *ErrorMessage) {
+	return h.feed.client.FeedStreamContext(ctx, h.feed.Resource(), url.PathEscape(h.name))
+}
+
+// Update creates or updates the stream
+//
+//		err := stream.Update(&StreamParams{Unit: &Unit{Label: "celsius", Symbol: "C"}})
+func (h StreamHandle) Update(params *StreamParams) *ErrorMessage {
+	return h.UpdateContext(context.Background(), params)
+}
+
+// UpdateContext is like Update but uses ctx for the request
+func (h StreamHandle) UpdateContext(ctx context.Context, params *StreamParams) *ErrorMessage {
+	return h.feed.client.UpdateFeedStreamWithParamsContext(ctx, h.feed.Resource(), url.PathEscape(h.name), params)
+}
+
+// Delete deletes the stream along with its values
+func (h StreamHandle) Delete() *ErrorMessage {
+	return h.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete but uses ctx for the request
+func (h StreamHandle) DeleteContext(ctx context.Context) *ErrorMessage {
+	return h.feed.client.DeleteFeedStreamContext(ctx, h.feed.Resource(), url.PathEscape(h.name))
+}
+
+// Values lists the latest values of the stream
+func (h StreamHandle) Values() (*Values, *ErrorMessage) {
+	return h.ValuesContext(context.Background())
+}
+
+// ValuesContext is like Values but uses ctx for the request
+func (h StreamHandle) ValuesContext(ctx context.Context) (*Values, *ErrorMessage) {
+	return h.QueryValuesContext(ctx, nil)
+}
+
+// QueryValues lists the values of the stream within a time window
+func (h StreamHandle) QueryValues(query *ValuesQuery) (*Values, *ErrorMessage) {
+	return h.QueryValuesContext(context.Background(), query)
+}
+
+// QueryValuesContext is like QueryValues but uses ctx for the request
+func (h StreamHandle) QueryValuesContext(ctx context.Context, query *ValuesQuery) (*Values, *ErrorMessage) {
+	return h.feed.client.QueryFeedStreamValuesContext(ctx, h.feed.Resource(), url.PathEscape(h.name), query)
+}
+
+// WalkValues calls fn for every value of the stream within the window of the
+// query, newest first, as Client.WalkFeedStreamValues does
+func (h StreamHandle) WalkValues(ctx context.Context, query *ValuesQuery, fn func(Value) error) *ErrorMessage {
+	return h.feed.client.WalkFeedStreamValues(ctx, h.feed.Resource(), url.PathEscape(h.name), query, fn)
+}
+
+// UpdateValues posts values to the stream
+//
+//		err := stream.UpdateValues(Value{At: NewTimestamp(time.Now()), Value: Float64Value(21.5)})
+func (h StreamHandle) UpdateValues(values ...Value) *ErrorMessage {
+	return h.UpdateValuesContext(context.Background(), values...)
+}
+
+// UpdateValuesContext is like UpdateValues but uses ctx for the request
+func (h StreamHandle) UpdateValuesContext(ctx context.Context, values ...Value) *ErrorMessage {
+	updateData := map[string]interface{}{"values": values}
+	return h.feed.client.UpdateFeedStreamValuesContext(ctx, h.feed.Resource(), url.PathEscape(h.name), updateData)
+}
+
+// DeleteValues deletes the values of the stream within a time range
+func (h StreamHandle) DeleteValues(valuesRange *ValuesRange) *ErrorMessage {
+	return h.DeleteValuesContext(context.Background(), valuesRange)
+}
+
+// DeleteValuesContext is like DeleteValues but uses ctx for the request
+func (h StreamHandle) DeleteValuesContext(ctx context.Context, valuesRange *ValuesRange) *ErrorMessage {
+	return h.feed.client.DeleteFeedStreamValuesContext(ctx, h.feed.Resource(), url.PathEscape(h.name), valuesRange)
+}
+
+// TriggerHandle refers to a trigger of a feed by its ID
+//
+//		trigger := client.FeedHandle("1234").Trigger("5678")
+//		err := trigger.Test()
+type TriggerHandle struct {
+	feed FeedHandle
+	id   string
+}
+
+// Feed returns the handle on the feed of the trigger
+func (h TriggerHandle) Feed() FeedHandle {
+	return h.feed
+}
+
+// ID returns the ID of the trigger
+func (h TriggerHandle) ID() string {
+	return h.id
+}
+
+// Get returns the trigger
+func (h TriggerHandle) Get() (*Trigger, *ErrorMessage) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses ctx for the request
+func (h TriggerHandle) GetContext(ctx context.Context) (*Trigger, *ErrorMessage) {
+	return h.feed.client.TriggerContext(ctx, h.feed.Resource(), url.PathEscape(h.id))
+}
+
+// Update updates the trigger
+//
+//		err := trigger.Update(&TriggerParams{Status: "disabled"})
+func (h TriggerHandle) Update(params *TriggerParams) *ErrorMessage {
+	return h.UpdateContext(context.Background(), params)
+}
+
+// UpdateContext is like Update but uses ctx for the request
+func (h TriggerHandle) UpdateContext(ctx context.Context, params *TriggerParams) *ErrorMessage {
+	return h.feed.client.UpdateTriggerWithParamsContext(ctx, h.feed.Resource(), url.PathEscape(h.id), params)
+}
+
+// Delete deletes the trigger
+func (h TriggerHandle) Delete() *ErrorMessage {
+	return h.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete but uses ctx for the request
+func (h TriggerHandle) DeleteContext(ctx context.Context) *ErrorMessage {
+	return h.feed.client.DeleteTriggerContext(ctx, h.feed.Resource(), url.PathEscape(h.id))
+}
+
+// Test makes the API call the callback of the trigger with a test event
+func (h TriggerHandle) Test() *ErrorMessage {
+	return h.TestContext(context.Background())
+}
+
+// TestContext is like Test but uses ctx for the request
+func (h TriggerHandle) TestContext(ctx context.Context) *ErrorMessage {
+	return h.feed.client.TestTriggerContext(ctx, h.feed.Resource(), url.PathEscape(h.id))
+}
+
+// BlueprintHandle refers to a blueprint by its ID
+//
+//		blueprint := client.BlueprintHandle("1234")
+//		err := blueprint.Update(&BlueprintParams{Description: "Updated"})
+type BlueprintHandle struct {
+	client *Client
+	id     string
+}
+
+// BlueprintHandle returns a handle on the blueprint with the given ID
+func (c *Client) BlueprintHandle(id string) BlueprintHandle {
+	return BlueprintHandle{client: c, id: id}
+}
+
+// ID returns the ID of the blueprint
+func (h BlueprintHandle) ID() string {
+	return h.id
+}
+
+// Feed returns a handle on the feed of the blueprint, which has the same ID
+func (h BlueprintHandle) Feed() FeedHandle {
+	return h.client.FeedHandle(h.id)
+}
+
+// Get returns the blueprint
+func (h BlueprintHandle) Get() (*Blueprint, *ErrorMessage) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses ctx for the request
+func (h BlueprintHandle) GetContext(ctx context.Context) (*Blueprint, *ErrorMessage) {
+	return h.client.BlueprintContext(ctx, url.PathEscape(h.id))
+}
+
+// Update updates the blueprint
+func (h BlueprintHandle) Update(params *BlueprintParams) *ErrorMessage {
+	return h.UpdateContext(context.Background(), params)
+}
+
+// UpdateContext is like Update but uses ctx for the request
+func (h BlueprintHandle) UpdateContext(ctx context.Context, params *BlueprintParams) *ErrorMessage {
+	return h.client.UpdateBlueprintWithParamsContext(ctx, url.PathEscape(h.id), params)
+}
+
+// Delete deletes the blueprint along with its feed
+func (h BlueprintHandle) Delete() *ErrorMessage {
+	return h.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete but uses ctx for the request
+func (h BlueprintHandle) DeleteContext(ctx context.Context) *ErrorMessage {
+	return h.client.DeleteBlueprintContext(ctx, url.PathEscape(h.id))
+}
+
+// BatchHandle refers to a batch by its ID
+//
+//		batch := client.BatchHandle("1234")
+//		err := batch.Update(&BatchParams{Description: "Updated"})
+type BatchHandle struct {
+	client *Client
+	id     string
+}
+
+// BatchHandle returns a handle on the batch with the given ID
+func (c *Client) BatchHandle(id string) BatchHandle {
+	return BatchHandle{client: c, id: id}
+}
+
+// ID returns the ID of the batch
+func (h BatchHandle) ID() string {
+	return h.id
+}
+
+// Feed returns a handle on the feed of the batch, which has the same ID
+func (h BatchHandle) Feed() FeedHandle {
+	return h.client.FeedHandle(h.id)
+}
+
+// Get returns the batch
+func (h BatchHandle) Get() (*Batch, *ErrorMessage) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses ctx for the request
+func (h BatchHandle) GetContext(ctx context.Context) (*Batch, *ErrorMessage) {
+	return h.client.BatchContext(ctx, url.PathEscape(h.id))
+}
+
+// Update updates the batch
+func (h BatchHandle) Update(params *BatchParams) *ErrorMessage {
+	return h.UpdateContext(context.Background(), params)
+}
+
+// UpdateContext is like Update but uses ctx for the request
+func (h BatchHandle) UpdateContext(ctx context.Context, params *BatchParams) *ErrorMessage {
+	return h.client.UpdateBatchWithParamsContext(ctx, url.PathEscape(h.id), params)
+}
+
+// Delete deletes the batch along with its feed
+func (h BatchHandle) Delete() (*Batch, *ErrorMessage) {
+	return h.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete but uses ctx for the request
+func (h BatchHandle) DeleteContext(ctx context.Context) (*Batch, *ErrorMessage) {
+	return h.client.DeleteBatchContext(ctx, url.PathEscape(h.id))
+}
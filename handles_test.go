@@ -0,0 +1,103 @@
+// Copyright (c) 2014 Jason Goecke
+// handles_test.go
+
+package m2x
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandlePaths(t *testing.T) {
+	var uris []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uris = append(uris, r.Method+" "+r.RequestURI)
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	feed := client.FeedHandle("12/34")
+	if feed.Resource() != "/feeds/12%2F34" {
+		t.Errorf("The feed ID was not escaped properly: %s", feed.Resource())
+	}
+	feed.Stream("room 1/temperature").Delete()
+	feed.Stream("humidity%").DeleteValues(&ValuesRange{From: time.Unix(0, 0), End: time.Unix(60, 0)})
+	feed.Trigger("a b").Delete()
+	client.BlueprintHandle("blue print").Delete()
+
+	expected := []string{
+		"DELETE /feeds/12%2F34/streams/room%201%2Ftemperature",
+		"DELETE /feeds/12%2F34/streams/humidity%25/values",
+		"DELETE /feeds/12%2F34/triggers/a%20b",
+		"DELETE /blueprints/blue%20print",
+	}
+	if len(uris) != len(expected) {
+		t.Fatalf("Expected %d requests, got %v", len(expected), uris)
+	}
+	for i, uri := range expected {
+		if uris[i] != uri {
+			t.Errorf("Expected %s, got %s", uri, uris[i])
+		}
+	}
+}
+
+func TestHandles(t *testing.T) {
+	client := newTestClient(t)
+	created, errorMessage := client.CreateBlueprintWithParams(&BlueprintParams{Name: "Go Handles Blueprint", Visibility: "private"})
+	if errorMessage != nil {
+		t.Fatalf("Did not create the blueprint properly: %v", errorMessage)
+	}
+	blueprint := client.BlueprintHandle(created.ID)
+	defer blueprint.Delete()
+	if errorMessage := blueprint.Update(&BlueprintParams{Description: "Updated through a handle"}); errorMessage != nil {
+		t.Errorf("Did not update the blueprint properly: %v", errorMessage)
+	}
+
+	stream := blueprint.Feed().Stream("room-1_temperature")
+	if errorMessage := stream.Update(&StreamParams{Unit: &Unit{Label: "celsius", Symbol: "C"}}); errorMessage != nil {
+		t.Fatalf("Did not create the stream properly: %v", errorMessage)
+	}
+	now := time.Now()
+	errorMessage = stream.UpdateValues(
+		Value{At: NewTimestamp(now.Add(-time.Minute)), Value: Float64Value(20)},
+		Value{At: NewTimestamp(now), Value: Float64Value(21)},
+	)
+	if errorMessage != nil {
+		t.Errorf("Did not post the values properly: %v", errorMessage)
+	}
+	if data, errorMessage := stream.Get(); errorMessage != nil || data.Name != "room-1_temperature" {
+		t.Errorf("Did not get the stream properly: %v", errorMessage)
+	}
+	if streams, _ := blueprint.Feed().Streams(); len(streams.Streams) != 1 {
+		t.Errorf("Did not list the stream properly")
+	}
+
+	trigger, errorMessage := blueprint.Feed().CreateTrigger(&TriggerParams{
+		Name:        "Too hot",
+		Stream:      stream.Name(),
+		Condition:   ">",
+		Value:       "30",
+		CallbackURL: "http://example.com/hook",
+	})
+	if errorMessage != nil {
+		t.Fatalf("Did not create the trigger properly: %v", errorMessage)
+	}
+	handle := blueprint.Feed().Trigger(trigger.ID)
+	if data, errorMessage := handle.Get(); errorMessage != nil || data.Stream != stream.Name() {
+		t.Errorf("Did not get the trigger properly: %v", errorMessage)
+	}
+	if errorMessage := handle.Delete(); errorMessage != nil {
+		t.Errorf("Did not delete the trigger properly: %v", errorMessage)
+	}
+
+	if errorMessage := stream.Delete(); errorMessage != nil {
+		t.Errorf("Did not delete the stream properly: %v", errorMessage)
+	}
+	if _, errorMessage := stream.Get(); !IsNotFound(errorMessage) {
+		t.Errorf("The stream should have been deleted: %v", errorMessage)
+	}
+}
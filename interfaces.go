@@ -0,0 +1,145 @@
+// Copyright (c) 2014 Jason Goecke
+// interfaces.go
+
+package m2x
+
+import "context"
+
+// API groups the operations of the M2X API implemented by Client. Code
+// depending on API, or only on the interfaces for the resources it uses,
+// may be tested with the in-memory mock of the m2xmock package instead of
+// a Client.
+//
+//		func countFeeds(feeds m2x.FeedsAPI) (int, *m2x.ErrorMessage) {
+//			result, errorMessage := feeds.Feeds()
+//			...
+//		}
+type API interface {
+	FeedsAPI
+	DatasourcesAPI
+	TriggersAPI
+	KeysAPI
+}
+
+// Client implements every interface
+var _ API = (*Client)(nil)
+
+// FeedsAPI is implemented by Client for feeds, their location, streams, values
+// and request log
+type FeedsAPI interface {
+	Feeds() (*Feeds, *ErrorMessage)
+	FeedsContext(ctx context.Context) (*Feeds, *ErrorMessage)
+	FeedsPage(opts *ListOptions) (*Feeds, *ErrorMessage)
+	FeedsPageContext(ctx context.Context, opts *ListOptions) (*Feeds, *ErrorMessage)
+	SearchFeeds(query *FeedQuery) (*Feeds, *ErrorMessage)
+	SearchFeedsContext(ctx context.Context, query *FeedQuery) (*Feeds, *ErrorMessage)
+	Feed(resource string) (*Feed, *ErrorMessage)
+	FeedContext(ctx context.Context, resource string) (*Feed, *ErrorMessage)
+	FeedLocation(resource string) (*Location, *ErrorMessage)
+	FeedLocationContext(ctx context.Context, resource string) (*Location, *ErrorMessage)
+	UpdateFeedLocation(resource string, updateData map[string]interface{}) *ErrorMessage
+	UpdateFeedLocationContext(ctx context.Context, resource string, updateData map[string]interface{}) *ErrorMessage
+	UpdateFeedLocationWithParams(resource string, params *LocationParams) *ErrorMessage
+	UpdateFeedLocationWithParamsContext(ctx context.Context, resource string, params *LocationParams) *ErrorMessage
+	FeedStreams(resource string) (*Streams, *ErrorMessage)
+	FeedStreamsContext(ctx context.Context, resource string) (*Streams, *ErrorMessage)
+	DeleteFeedStream(resource string, name string) *ErrorMessage
+	DeleteFeedStreamContext(ctx context.Context, resource string, name string) *ErrorMessage
+	FeedStream(resource string, name string) (*Stream, *ErrorMessage)
+	FeedStreamContext(ctx context.Context, resource string, name string) (*Stream, *ErrorMessage)
+	UpdateFeedStream(resource string, name string, updateData map[string]interface{}) *ErrorMessage
+	UpdateFeedStreamContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *ErrorMessage
+	UpdateFeedStreamWithParams(resource string, name string, params *StreamParams) *ErrorMessage
+	UpdateFeedStreamWithParamsContext(ctx context.Context, resource string, name string, params *StreamParams) *ErrorMessage
+	FeedStreamValues(resource string, name string) (*Values, *ErrorMessage)
+	FeedStreamValuesContext(ctx context.Context, resource string, name string) (*Values, *ErrorMessage)
+	QueryFeedStreamValues(resource string, name string, query *ValuesQuery) (*Values, *ErrorMessage)
+	QueryFeedStreamValuesContext(ctx context.Context, resource string, name string, query *ValuesQuery) (*Values, *ErrorMessage)
+	WalkFeedStreamValues(ctx context.Context, resource string, name string, query *ValuesQuery, fn func(Value) error) *ErrorMessage
+	UpdateFeedStreamValues(resource string, name string, updateData map[string]interface{}) *ErrorMessage
+	UpdateFeedStreamValuesContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *ErrorMessage
+	DeleteFeedStreamValues(resource string, name string, valuesRange *ValuesRange) *ErrorMessage
+	DeleteFeedStreamValuesContext(ctx context.Context, resource string, name string, valuesRange *ValuesRange) *ErrorMessage
+	RequestLog(resource string) (*Requests, *ErrorMessage)
+	RequestLogContext(ctx context.Context, resource string) (*Requests, *ErrorMessage)
+	UpdateFeedValues(resource string, values *FeedValues) *ErrorMessage
+	UpdateFeedValuesContext(ctx context.Context, resource string, values *FeedValues) *ErrorMessage
+}
+
+// DatasourcesAPI is implemented by Client for blueprints and batches
+type DatasourcesAPI interface {
+	CreateBlueprint(blueprint map[string]string) (*Blueprint, *ErrorMessage)
+	CreateBlueprintContext(ctx context.Context, blueprint map[string]string) (*Blueprint, *ErrorMessage)
+	CreateBlueprintWithParams(params *BlueprintParams) (*Blueprint, *ErrorMessage)
+	CreateBlueprintWithParamsContext(ctx context.Context, params *BlueprintParams) (*Blueprint, *ErrorMessage)
+	DeleteBlueprint(id string) *ErrorMessage
+	DeleteBlueprintContext(ctx context.Context, id string) *ErrorMessage
+	Blueprints() (*Blueprints, *ErrorMessage)
+	BlueprintsContext(ctx context.Context) (*Blueprints, *ErrorMessage)
+	BlueprintsPage(opts *ListOptions) (*Blueprints, *ErrorMessage)
+	BlueprintsPageContext(ctx context.Context, opts *ListOptions) (*Blueprints, *ErrorMessage)
+	Blueprint(id string) (*Blueprint, *ErrorMessage)
+	BlueprintContext(ctx context.Context, id string) (*Blueprint, *ErrorMessage)
+	UpdateBlueprint(id string, updateData map[string]string) *ErrorMessage
+	UpdateBlueprintContext(ctx context.Context, id string, updateData map[string]string) *ErrorMessage
+	UpdateBlueprintWithParams(id string, params *BlueprintParams) *ErrorMessage
+	UpdateBlueprintWithParamsContext(ctx context.Context, id string, params *BlueprintParams) *ErrorMessage
+	CreateBatch(batch map[string]string) (*Batch, *ErrorMessage)
+	CreateBatchContext(ctx context.Context, batch map[string]string) (*Batch, *ErrorMessage)
+	CreateBatchWithParams(params *BatchParams) (*Batch, *ErrorMessage)
+	CreateBatchWithParamsContext(ctx context.Context, params *BatchParams) (*Batch, *ErrorMessage)
+	DeleteBatch(id string) (*Batch, *ErrorMessage)
+	DeleteBatchContext(ctx context.Context, id string) (*Batch, *ErrorMessage)
+	Batches() (*Batches, *ErrorMessage)
+	BatchesContext(ctx context.Context) (*Batches, *ErrorMessage)
+	BatchesPage(opts *ListOptions) (*Batches, *ErrorMessage)
+	BatchesPageContext(ctx context.Context, opts *ListOptions) (*Batches, *ErrorMessage)
+	Batch(id string) (*Batch, *ErrorMessage)
+	BatchContext(ctx context.Context, id string) (*Batch, *ErrorMessage)
+	UpdateBatch(id string, updateData map[string]string) *ErrorMessage
+	UpdateBatchContext(ctx context.Context, id string, updateData map[string]string) *ErrorMessage
+	UpdateBatchWithParams(id string, params *BatchParams) *ErrorMessage
+	UpdateBatchWithParamsContext(ctx context.Context, id string, params *BatchParams) *ErrorMessage
+}
+
+// TriggersAPI is implemented by Client for the triggers of feeds
+type TriggersAPI interface {
+	CreateTrigger(resource string, trigger map[string]string) (*Trigger, *ErrorMessage)
+	CreateTriggerContext(ctx context.Context, resource string, trigger map[string]string) (*Trigger, *ErrorMessage)
+	CreateTriggerWithParams(resource string, params *TriggerParams) (*Trigger, *ErrorMessage)
+	CreateTriggerWithParamsContext(ctx context.Context, resource string, params *TriggerParams) (*Trigger, *ErrorMessage)
+	DeleteTrigger(resource string, id string) *ErrorMessage
+	DeleteTriggerContext(ctx context.Context, resource string, id string) *ErrorMessage
+	Triggers(resource string) (*Triggers, *ErrorMessage)
+	TriggersContext(ctx context.Context, resource string) (*Triggers, *ErrorMessage)
+	Trigger(resource string, id string) (*Trigger, *ErrorMessage)
+	TriggerContext(ctx context.Context, resource string, id string) (*Trigger, *ErrorMessage)
+	UpdateTrigger(resource string, id string, updateData map[string]string) *ErrorMessage
+	UpdateTriggerContext(ctx context.Context, resource string, id string, updateData map[string]string) *ErrorMessage
+	UpdateTriggerWithParams(resource string, id string, params *TriggerParams) *ErrorMessage
+	UpdateTriggerWithParamsContext(ctx context.Context, resource string, id string, params *TriggerParams) *ErrorMessage
+	TestTrigger(resource string, name string) *ErrorMessage
+	TestTriggerContext(ctx context.Context, resource string, name string) *ErrorMessage
+}
+
+// KeysAPI is implemented by Client for API keys
+type KeysAPI interface {
+	CreateKey(key map[string]interface{}) (*Key, *ErrorMessage)
+	CreateKeyContext(ctx context.Context, key map[string]interface{}) (*Key, *ErrorMessage)
+	CreateKeyWithParams(params *KeyParams) (*Key, *ErrorMessage)
+	CreateKeyWithParamsContext(ctx context.Context, params *KeyParams) (*Key, *ErrorMessage)
+	DeleteKey(id string) *ErrorMessage
+	DeleteKeyContext(ctx context.Context, id string) *ErrorMessage
+	Keys() (*Keys, *ErrorMessage)
+	KeysContext(ctx context.Context) (*Keys, *ErrorMessage)
+	KeysPage(opts *ListOptions) (*Keys, *ErrorMessage)
+	KeysPageContext(ctx context.Context, opts *ListOptions) (*Keys, *ErrorMessage)
+	SearchKeys(query *KeyQuery) (*Keys, *ErrorMessage)
+	SearchKeysContext(ctx context.Context, query *KeyQuery) (*Keys, *ErrorMessage)
+	Key(id string) (*Key, *ErrorMessage)
+	KeyContext(ctx context.Context, id string) (*Key, *ErrorMessage)
+	UpdateKey(id string, updateData map[string]interface{}) *ErrorMessage
+	UpdateKeyContext(ctx context.Context, id string, updateData map[string]interface{}) *ErrorMessage
+	UpdateKeyWithParams(id string, params *KeyParams) *ErrorMessage
+	UpdateKeyWithParamsContext(ctx context.Context, id string, params *KeyParams) *ErrorMessage
+}
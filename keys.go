@@ -4,7 +4,9 @@
 package m2x
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 )
 
 // Keys represents a Keys response from the M2X API (https://m2x.att.com/developer/documentation/keys)
@@ -18,15 +20,79 @@ type Keys struct {
 
 // Key represents a single Key
 type Key struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Key         string   `json:"key"`
-	Master      bool     `json:"master"`
-	Feed        string   `json:"feed"`
-	Stream      string   `json:"stream"`
-	ExpiresAt   string   `json:"expires_at"`
-	Expired     string   `json:"expired"`
-	Permissions []string `json:"permissions"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Key         string    `json:"key"`
+	Master      bool      `json:"master"`
+	Feed        string    `json:"feed"`
+	Stream      string    `json:"stream"`
+	ExpiresAt   Timestamp `json:"expires_at"`
+	Expired     string    `json:"expired"`
+	Permissions []string  `json:"permissions"`
+}
+
+// KeyParams holds the fields used to create or update a key. A key may be
+// restricted to a feed, and further to one of its streams.
+// Empty fields are left out, so an update only changes the fields that are set.
+type KeyParams struct {
+	Name        string     `json:"name,omitempty"`
+	Permissions []string   `json:"permissions,omitempty"`
+	Feed        string     `json:"feed,omitempty"`
+	Stream      string     `json:"stream,omitempty"`
+	ExpiresAt   *Timestamp `json:"expires_at,omitempty"`
+}
+
+// Checks the key params, requiring a name and permissions on creation
+func (p *KeyParams) validate(create bool) Error {
+	if p == nil {
+		p = &KeyParams{}
+	}
+	errs := Error{}
+	if create {
+		errs.require("name", p.Name)
+		if len(p.Permissions) == 0 {
+			errs.add("permissions", "can't be blank")
+		}
+	}
+	for _, permission := range p.Permissions {
+		errs.oneOf("permissions", permission, "GET", "POST", "PUT", "DELETE")
+	}
+	if p.Stream != "" && p.Feed == "" {
+		errs.add("stream", "requires a feed")
+	}
+	return errs
+}
+
+// KeyQuery filters the keys of the /keys resource by the feed, and optionally
+// the stream, they are restricted to. Feed may be given as an ID or as a feed
+// resource such as "/feeds/1234".
+type KeyQuery struct {
+	ListOptions
+	Feed   string
+	Stream string
+}
+
+// Checks the key query
+func (q *KeyQuery) validate() Error {
+	errs := Error{}
+	if q != nil && q.Stream != "" && q.Feed == "" {
+		errs.add("stream", "requires a feed")
+	}
+	return errs
+}
+
+// Returns the query string for the filters, starting with "?" if not empty
+func (q *KeyQuery) query() string {
+	if q == nil {
+		return ""
+	}
+	params := q.ListOptions.values()
+	setParam(params, "feed", strings.TrimPrefix(q.Feed, "/feeds/"))
+	setParam(params, "stream", q.Stream)
+	if len(params) > 0 {
+		return "?" + params.Encode()
+	}
+	return ""
 }
 
 // CreateKey creates a key
@@ -36,21 +102,53 @@ type Key struct {
 // 		keyData["name"] = name
 // 		keyData["permissions"] = [...]string{"GET", "PUT"}
 // 		key, err := client.CreateKey(keyData)
+//
+// Deprecated: use CreateKeyWithParams, which checks the data before sending it.
 func (c *Client) CreateKey(key map[string]interface{}) (*Key, *ErrorMessage) {
+	return c.CreateKeyContext(context.Background(), key)
+}
+
+// CreateKeyContext is like CreateKey but uses ctx for the request
+//
+// Deprecated: use CreateKeyWithParamsContext, which checks the data before sending it.
+func (c *Client) CreateKeyContext(ctx context.Context, key map[string]interface{}) (*Key, *ErrorMessage) {
+	return c.createKey(ctx, key)
+}
+
+// CreateKeyWithParams creates a key, checking the params first
+//
+//		key, err := client.CreateKeyWithParams(&KeyParams{
+//			Name:        "Go Created Key",
+//			Permissions: []string{"GET", "PUT"},
+//		})
+func (c *Client) CreateKeyWithParams(params *KeyParams) (*Key, *ErrorMessage) {
+	return c.CreateKeyWithParamsContext(context.Background(), params)
+}
+
+// CreateKeyWithParamsContext is like CreateKeyWithParams but uses ctx for the request
+func (c *Client) CreateKeyWithParamsContext(ctx context.Context, params *KeyParams) (*Key, *ErrorMessage) {
+	if errs := params.validate(true); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	return c.createKey(ctx, params)
+}
+
+// Sends the data of CreateKey, given either as a map or as params
+func (c *Client) createKey(ctx context.Context, key interface{}) (*Key, *ErrorMessage) {
 	data, err := json.Marshal(key)
 	if err != nil {
 		return nil, simpleErrorMessage(err, 0)
 	}
 
-	result, statusCode, postErr := post(c.APIBase+"/keys", data)
+	result, statusCode, postErr := c.post(ctx, c.APIBase+"/keys", data)
 	if postErr != nil {
 		return nil, simpleErrorMessage(postErr, statusCode)
 	}
 	if statusCode == 201 {
 		newKey := &Key{}
-		unmarshalErr := json.Unmarshal(result, &newKey)
+		unmarshalErr := json.Unmarshal(result.body, &newKey)
 		if unmarshalErr != nil {
-			return nil, simpleErrorMessage(err, statusCode)
+			return nil, simpleErrorMessage(unmarshalErr, statusCode)
 		}
 		return newKey, nil
 	}
@@ -61,7 +159,12 @@ func (c *Client) CreateKey(key map[string]interface{}) (*Key, *ErrorMessage) {
 //
 //		err := client.DeleteKey("1234")
 func (c *Client) DeleteKey(id string) *ErrorMessage {
-	result, statusCode, err := delete(c.APIBase+"/keys", id)
+	return c.DeleteKeyContext(context.Background(), id)
+}
+
+// DeleteKeyContext is like DeleteKey but uses ctx for the request
+func (c *Client) DeleteKeyContext(ctx context.Context, id string) *ErrorMessage {
+	result, statusCode, err := c.delete(ctx, c.APIBase+"/keys", id)
 	if err != nil {
 		return simpleErrorMessage(err, statusCode)
 	}
@@ -74,28 +177,73 @@ func (c *Client) DeleteKey(id string) *ErrorMessage {
 // Keys gets a list of keys from the /keys resource
 //
 //		keys, err := client.Keys()
-func (c *Client) Keys() (*Blueprints, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/keys")
+func (c *Client) Keys() (*Keys, *ErrorMessage) {
+	return c.KeysContext(context.Background())
+}
+
+// KeysContext is like Keys but uses ctx for the request
+func (c *Client) KeysContext(ctx context.Context) (*Keys, *ErrorMessage) {
+	return c.SearchKeysContext(ctx, nil)
+}
+
+// KeysPage gets a single page of keys
+//
+//		keys, err := client.KeysPage(&ListOptions{Page: 2, Limit: 10})
+func (c *Client) KeysPage(opts *ListOptions) (*Keys, *ErrorMessage) {
+	return c.KeysPageContext(context.Background(), opts)
+}
+
+// KeysPageContext is like KeysPage but uses ctx for the request
+func (c *Client) KeysPageContext(ctx context.Context, opts *ListOptions) (*Keys, *ErrorMessage) {
+	query := &KeyQuery{}
+	if opts != nil {
+		query.ListOptions = *opts
+	}
+	return c.SearchKeysContext(ctx, query)
+}
+
+// SearchKeys gets a page of the keys restricted to a feed, or to a stream of
+// that feed
+//
+//		keys, err := client.SearchKeys(&KeyQuery{Feed: "1234", Stream: "temperature"})
+func (c *Client) SearchKeys(query *KeyQuery) (*Keys, *ErrorMessage) {
+	return c.SearchKeysContext(context.Background(), query)
+}
+
+// SearchKeysContext is like SearchKeys but uses ctx for the request
+func (c *Client) SearchKeysContext(ctx context.Context, query *KeyQuery) (*Keys, *ErrorMessage) {
+	if errs := query.validate(); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	result, statusCode, err := c.get(ctx, c.APIBase+"/keys"+query.query())
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
-	data, err := parseBlueprints(result)
-	if err != nil {
-		return nil, simpleErrorMessage(err, 0)
+	if statusCode == 200 {
+		data, err := parseKeys(result.body)
+		if err != nil {
+			return nil, simpleErrorMessage(err, statusCode)
+		}
+		return data, nil
 	}
-	return data, nil
+	return nil, generateErrorMessage(result, statusCode)
 }
 
-// Key gets a list of blueprints from the /key resource
+// Key gets a key
 //
-//		key, err := client.Key()
+//		key, err := client.Key("1234")
 func (c *Client) Key(id string) (*Key, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + "/keys/" + id)
+	return c.KeyContext(context.Background(), id)
+}
+
+// KeyContext is like Key but uses ctx for the request
+func (c *Client) KeyContext(ctx context.Context, id string) (*Key, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+"/keys/"+id)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseKey(result)
+		data, err := parseKey(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -108,12 +256,41 @@ func (c *Client) Key(id string) (*Key, *ErrorMessage) {
 //
 // 		keyData["name"] = "Go key"
 // 		err := client.UpdateKey("/feeds/1234", keyData)
+//
+// Deprecated: use UpdateKeyWithParams, which checks the data before sending it.
 func (c *Client) UpdateKey(id string, updateData map[string]interface{}) *ErrorMessage {
+	return c.UpdateKeyContext(context.Background(), id, updateData)
+}
+
+// UpdateKeyContext is like UpdateKey but uses ctx for the request
+//
+// Deprecated: use UpdateKeyWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateKeyContext(ctx context.Context, id string, updateData map[string]interface{}) *ErrorMessage {
+	return c.updateKey(ctx, id, updateData)
+}
+
+// UpdateKeyWithParams updates the fields of a key set in params
+//
+//		err := client.UpdateKeyWithParams("1234", &KeyParams{Name: "Go key"})
+func (c *Client) UpdateKeyWithParams(id string, params *KeyParams) *ErrorMessage {
+	return c.UpdateKeyWithParamsContext(context.Background(), id, params)
+}
+
+// UpdateKeyWithParamsContext is like UpdateKeyWithParams but uses ctx for the request
+func (c *Client) UpdateKeyWithParamsContext(ctx context.Context, id string, params *KeyParams) *ErrorMessage {
+	if errs := params.validate(false); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateKey(ctx, id, params)
+}
+
+// Sends the data of UpdateKey, given either as a map or as params
+func (c *Client) updateKey(ctx context.Context, id string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, postErr := put(c.APIBase+"/keys/"+id, data)
+	result, statusCode, postErr := c.put(ctx, c.APIBase+"/keys/"+id, data)
 	if postErr != nil {
 		return simpleErrorMessage(postErr, statusCode)
 	}
@@ -4,7 +4,7 @@
 package m2x
 
 import (
-	"os"
+	"context"
 	"testing"
 	"time"
 )
@@ -79,15 +79,15 @@ func TestParseKey(t *testing.T) {
 }
 
 func TestListKeys(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
-	result, _ := client.Keys()
-	if result.CurrentPage != 0 {
-		t.Errorf("Listing the feeds did not work properly")
+	client := newTestClient(t)
+	result, err := client.Keys()
+	if err != nil || result.CurrentPage != 1 {
+		t.Errorf("Listing the keys did not work properly")
 	}
 }
 
 func TestListBadKeyId(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 	_, errorMessage := client.Key("1234")
 	if errorMessage.StatusCode != 404 || errorMessage.Message != "The specified key does not exist" {
 		t.Errorf("We did not get the proper error message or code back")
@@ -95,7 +95,7 @@ func TestListBadKeyId(t *testing.T) {
 }
 
 func TestCreateAndListAndUpdateAndDeleteKey(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newTestClient(t)
 
 	// Create a new key
 	key := make(map[string]interface{})
@@ -133,3 +133,67 @@ func TestCreateAndListAndUpdateAndDeleteKey(t *testing.T) {
 		t.Errorf("Did not delete key properly")
 	}
 }
+
+func TestKeyParamsValidation(t *testing.T) {
+	if errs := (&KeyParams{Name: "Go key", Permissions: []string{"GET", "PUT"}}).validate(true); len(errs) != 0 {
+		t.Errorf("Valid key params were rejected: %v", errs)
+	}
+	errs := (&KeyParams{Permissions: []string{"GET", "PATCH"}, Stream: "temperature"}).validate(true)
+	if len(errs["name"]) != 1 || len(errs["permissions"]) != 1 || len(errs["stream"]) != 1 {
+		t.Errorf("Invalid key params were not rejected properly: %v", errs)
+	}
+	if errs := (&KeyParams{Name: "Go key"}).validate(false); len(errs) != 0 {
+		t.Errorf("Permissions should not be required on update")
+	}
+}
+
+func TestSearchKeys(t *testing.T) {
+	server, client, last := newRecordingServer(200, `{"keys":[{"name":"Feed Key","key":"1234","feed":"/feeds/1234","stream":"temperature"}],"total":1,"pages":1,"limit":10,"current_page":1}`)
+	defer server.Close()
+
+	keys, errorMessage := client.Keys()
+	if errorMessage != nil || len(keys.Keys) != 1 || keys.Keys[0].Name != "Feed Key" || keys.CurrentPage != 1 {
+		t.Errorf("Did not list the keys properly: %v", errorMessage)
+	}
+
+	keys, errorMessage = client.SearchKeys(&KeyQuery{Feed: "/feeds/1234", Stream: "temperature"})
+	if errorMessage != nil || keys.Keys[0].Stream != "temperature" {
+		t.Errorf("Did not search the keys properly: %v", errorMessage)
+	}
+	if _, path, rawQuery, _ := last.get(); path != "/keys" || rawQuery != "feed=1234&stream=temperature" {
+		t.Errorf("Key filters were not encoded properly: %s", rawQuery)
+	}
+
+	count := 0
+	feedKeys := client.IterateKeySearch(context.Background(), &KeyQuery{Feed: "1234"})
+	for feedKeys.Next() {
+		count++
+	}
+	if count != 1 || feedKeys.Err() != nil {
+		t.Errorf("Did not iterate over the keys of the feed properly")
+	}
+
+	if _, errorMessage = client.SearchKeys(&KeyQuery{Stream: "temperature"}); !IsValidation(errorMessage) {
+		t.Errorf("A stream filter without a feed should not have been sent")
+	}
+}
+
+func TestKeysError(t *testing.T) {
+	server, client, _ := newRecordingServer(401, `{"message":"Unauthorized"}`)
+	defer server.Close()
+
+	keys, errorMessage := client.Keys()
+	if keys != nil || !IsUnauthorized(errorMessage) {
+		t.Errorf("The status code of the response was ignored")
+	}
+}
+
+func TestCreateKeyMalformedResponse(t *testing.T) {
+	server, client, _ := newRecordingServer(201, `{"key":`)
+	defer server.Close()
+
+	key, errorMessage := client.CreateKeyWithParams(&KeyParams{Name: "Reader", Permissions: []string{"GET"}})
+	if key != nil || errorMessage == nil || errorMessage.Err == nil {
+		t.Errorf("A malformed response should have been reported: %v", errorMessage)
+	}
+}
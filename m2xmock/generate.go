@@ -0,0 +1,149 @@
+// Copyright (c) 2014 Jason Goecke
+// generate.go
+
+//go:build ignore
+
+// Generates mock_gen.go, the methods of Mock, from the interfaces in
+// interfaces.go of the m2x package
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// Methods written by hand in mock.go, whose result types are still generated
+var handwritten = map[string]bool{
+	"WalkFeedStreamValues": true,
+}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../interfaces.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var types, methods bytes.Buffer
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			iface, ok := spec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			for _, field := range iface.Methods.List {
+				// Embedded interfaces are generated from their own declarations
+				if len(field.Names) == 0 {
+					continue
+				}
+				qualify(field.Type)
+				generate(&types, &methods, spec.Name.Name, field.Names[0].Name, field.Type.(*ast.FuncType))
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprint(&out, "// Code generated by generate.go. DO NOT EDIT.\n\n")
+	fmt.Fprint(&out, "package m2xmock\n\n")
+	fmt.Fprint(&out, "import (\n\t\"context\"\n\t\"reflect\"\n\n\tm2x \"github.com/jsgoecke/m2x-go\"\n)\n\n")
+	fmt.Fprint(&out, "// The results of each method of m2x.API\n")
+	fmt.Fprintf(&out, "var resultTypes = map[string][]reflect.Type{\n%s}\n", types.String())
+	out.Write(methods.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("mock_gen.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Prefixes the types of the m2x package used in expr with the package name
+func qualify(expr ast.Expr) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Field:
+			// Only the type of a parameter may need to be qualified
+			qualify(node.Type)
+			return false
+		case *ast.Ident:
+			if ast.IsExported(node.Name) {
+				node.Name = "m2x." + node.Name
+			}
+		}
+		return true
+	})
+}
+
+// Writes the result types of a method, and the method itself
+func generate(types, methods *bytes.Buffer, iface string, name string, fn *ast.FuncType) {
+	var params, args []string
+	for _, field := range fn.Params.List {
+		for _, ident := range field.Names {
+			params = append(params, ident.Name+" "+expression(field.Type))
+			args = append(args, ident.Name)
+		}
+	}
+	var results, zeros, assertions, values []string
+	for i, field := range fn.Results.List {
+		result := expression(field.Type)
+		if _, ok := field.Type.(*ast.StarExpr); !ok {
+			log.Fatalf("%s returns %s, only pointers are supported", name, result)
+		}
+		results = append(results, result)
+		zeros = append(zeros, "nil")
+		assertions = append(assertions, fmt.Sprintf("r%d, _ := results[%d].(%s)", i, i, result))
+		values = append(values, fmt.Sprintf("r%d", i))
+	}
+	if results[len(results)-1] != "*m2x.ErrorMessage" {
+		log.Fatalf("%s does not return an *m2x.ErrorMessage last", name)
+	}
+	zeros[len(zeros)-1] = fmt.Sprintf("unscripted(%q)", name)
+
+	fmt.Fprintf(types, "%q: {", name)
+	for _, result := range results {
+		fmt.Fprintf(types, "reflect.TypeOf((*%s)(nil)).Elem(), ", result)
+	}
+	fmt.Fprint(types, "},\n")
+	if handwritten[name] {
+		return
+	}
+
+	signature := "(" + strings.Join(results, ", ") + ")"
+	if len(results) == 1 {
+		signature = results[0]
+	}
+	called := fmt.Sprintf("m.called(%q)", name)
+	if len(args) > 0 {
+		called = fmt.Sprintf("m.called(%q, %s)", name, strings.Join(args, ", "))
+	}
+	fmt.Fprintf(methods, "\n// %s implements m2x.%s\n", name, iface)
+	fmt.Fprintf(methods, "func (m *Mock) %s(%s) %s {\n", name, strings.Join(params, ", "), signature)
+	fmt.Fprintf(methods, "\tresults := %s\n", called)
+	fmt.Fprintf(methods, "\tif results == nil {\n\t\treturn %s\n\t}\n", strings.Join(zeros, ", "))
+	fmt.Fprintf(methods, "\t%s\n", strings.Join(assertions, "\n\t"))
+	fmt.Fprintf(methods, "\treturn %s\n}\n", strings.Join(values, ", "))
+}
+
+// Returns the source of an expression
+func expression(expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
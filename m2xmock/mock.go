@@ -0,0 +1,179 @@
+// Copyright (c) 2014 Jason Goecke
+// mock.go
+
+// Package m2xmock provides Mock, an in-memory implementation of m2x.API for
+// unit tests. It records the calls made to it and answers them with scripted
+// results, so code depending on the interfaces of the m2x package may be tested
+// without HTTP servers.
+//
+//		mock := &m2xmock.Mock{}
+//		mock.Return("Feed", &m2x.Feed{ID: "1234", Name: "Sensor"}, nil)
+//		feed, errorMessage := mock.Feed("/feeds/1234")
+//		calls := mock.CallsTo("Feed") // [{Feed [/feeds/1234]}]
+//
+// The methods of Mock are generated from the interfaces in interfaces.go of the
+// m2x package by running go generate.
+package m2xmock
+
+//go:generate go run generate.go
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	m2x "github.com/jsgoecke/m2x-go"
+)
+
+// Call is a call made to a Mock
+type Call struct {
+	// Method is the name of the method called
+	Method string
+	// Args are the arguments of the call, including the context of the
+	// ...Context methods
+	Args []interface{}
+}
+
+// Mock implements m2x.API in memory. Calls are answered with the results given
+// to Return, in order, then with those given to ReturnAlways. A call to a method
+// without any scripted results returns an *m2x.ErrorMessage saying so.
+//
+// The zero value is ready to use, and a Mock may be used by several goroutines.
+type Mock struct {
+	mu     sync.Mutex
+	calls  []Call
+	next   map[string][][]interface{}
+	always map[string][]interface{}
+	walked []m2x.Value
+}
+
+// Mock implements every interface
+var _ m2x.API = (*Mock)(nil)
+
+// Return scripts the results of the next call to method that is not already
+// scripted. Results are given in the order returned by the method, nil being
+// allowed for any of them. Return panics if the method does not exist or if
+// the results do not match the ones of the method.
+//
+//		mock.Return("CreateTriggerWithParams", &m2x.Trigger{ID: "1"}, nil)
+//		mock.Return("DeleteTrigger", &m2x.ErrorMessage{Message: "Not found", StatusCode: 404})
+func (m *Mock) Return(method string, results ...interface{}) {
+	check(method, results)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.next == nil {
+		m.next = make(map[string][][]interface{})
+	}
+	m.next[method] = append(m.next[method], results)
+}
+
+// ReturnAlways scripts the results of every call to method once the results
+// given to Return have been used. It panics like Return.
+func (m *Mock) ReturnAlways(method string, results ...interface{}) {
+	check(method, results)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.always == nil {
+		m.always = make(map[string][]interface{})
+	}
+	m.always[method] = results
+}
+
+// WalkValues scripts the values passed to the function given to every call to
+// WalkFeedStreamValues, in order. Once they have been walked, the call returns
+// the results given to Return or ReturnAlways, or nil if there are none.
+//
+//		mock.WalkValues(m2x.Value{At: m2x.NewTimestamp(now), Value: m2x.Float64Value(21.5)})
+func (m *Mock) WalkValues(values ...m2x.Value) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.walked = make([]m2x.Value, len(values))
+	copy(m.walked, values)
+}
+
+// WalkFeedStreamValues implements m2x.FeedsAPI. It passes the values given to
+// WalkValues to fn, stopping at the first error fn returns.
+func (m *Mock) WalkFeedStreamValues(ctx context.Context, resource string, name string, query *m2x.ValuesQuery, fn func(m2x.Value) error) *m2x.ErrorMessage {
+	results := m.called("WalkFeedStreamValues", ctx, resource, name, query, fn)
+	m.mu.Lock()
+	values := m.walked
+	m.mu.Unlock()
+	for _, value := range values {
+		if err := fn(value); err != nil {
+			return &m2x.ErrorMessage{Message: err.Error(), Err: err}
+		}
+	}
+	if results == nil {
+		if values != nil {
+			return nil
+		}
+		return unscripted("WalkFeedStreamValues")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// Calls returns the calls made to the mock, in order
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to method, in order
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made to the mock, the scripted results and the
+// values given to WalkValues
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.next = nil
+	m.always = nil
+	m.walked = nil
+}
+
+// Records a call and returns its scripted results, or nil if there are none
+func (m *Mock) called(method string, args ...interface{}) []interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	if next := m.next[method]; len(next) > 0 {
+		m.next[method] = next[1:]
+		return next[0]
+	}
+	return m.always[method]
+}
+
+// Returns the error of a call to a method without any scripted results
+func unscripted(method string) *m2x.ErrorMessage {
+	return &m2x.ErrorMessage{Message: "m2xmock: no results scripted for " + method}
+}
+
+// Panics unless results may be returned by method
+func check(method string, results []interface{}) {
+	types, ok := resultTypes[method]
+	if !ok {
+		panic("m2xmock: unknown method " + method)
+	}
+	if len(results) != len(types) {
+		panic(fmt.Sprintf("m2xmock: %s returns %d results, got %d", method, len(types), len(results)))
+	}
+	for i, result := range results {
+		if result != nil && !reflect.TypeOf(result).AssignableTo(types[i]) {
+			panic(fmt.Sprintf("m2xmock: result %d of %s is a %s, got %T", i+1, method, types[i], result))
+		}
+	}
+}
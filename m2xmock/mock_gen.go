@@ -0,0 +1,1199 @@
+// Code generated by generate.go. DO NOT EDIT.
+
+package m2xmock
+
+import (
+	"context"
+	"reflect"
+
+	m2x "github.com/jsgoecke/m2x-go"
+)
+
+// The results of each method of m2x.API
+var resultTypes = map[string][]reflect.Type{
+	"Feeds":                               {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedsContext":                        {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedsPage":                           {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedsPageContext":                    {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"SearchFeeds":                         {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"SearchFeedsContext":                  {reflect.TypeOf((**m2x.Feeds)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Feed":                                {reflect.TypeOf((**m2x.Feed)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedContext":                         {reflect.TypeOf((**m2x.Feed)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedLocation":                        {reflect.TypeOf((**m2x.Location)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedLocationContext":                 {reflect.TypeOf((**m2x.Location)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedLocation":                  {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedLocationContext":           {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedLocationWithParams":        {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedLocationWithParamsContext": {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStreams":                         {reflect.TypeOf((**m2x.Streams)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStreamsContext":                  {reflect.TypeOf((**m2x.Streams)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteFeedStream":                    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteFeedStreamContext":             {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStream":                          {reflect.TypeOf((**m2x.Stream)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStreamContext":                   {reflect.TypeOf((**m2x.Stream)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStream":                    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStreamContext":             {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStreamWithParams":          {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStreamWithParamsContext":   {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStreamValues":                    {reflect.TypeOf((**m2x.Values)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"FeedStreamValuesContext":             {reflect.TypeOf((**m2x.Values)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"QueryFeedStreamValues":               {reflect.TypeOf((**m2x.Values)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"QueryFeedStreamValuesContext":        {reflect.TypeOf((**m2x.Values)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"WalkFeedStreamValues":                {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStreamValues":              {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedStreamValuesContext":       {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteFeedStreamValues":              {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteFeedStreamValuesContext":       {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"RequestLog":                          {reflect.TypeOf((**m2x.Requests)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"RequestLogContext":                   {reflect.TypeOf((**m2x.Requests)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedValues":                    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateFeedValuesContext":             {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBlueprint":                     {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBlueprintContext":              {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBlueprintWithParams":           {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBlueprintWithParamsContext":    {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteBlueprint":                     {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteBlueprintContext":              {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Blueprints":                          {reflect.TypeOf((**m2x.Blueprints)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BlueprintsContext":                   {reflect.TypeOf((**m2x.Blueprints)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BlueprintsPage":                      {reflect.TypeOf((**m2x.Blueprints)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BlueprintsPageContext":               {reflect.TypeOf((**m2x.Blueprints)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Blueprint":                           {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BlueprintContext":                    {reflect.TypeOf((**m2x.Blueprint)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBlueprint":                     {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBlueprintContext":              {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBlueprintWithParams":           {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBlueprintWithParamsContext":    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBatch":                         {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBatchContext":                  {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBatchWithParams":               {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateBatchWithParamsContext":        {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteBatch":                         {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteBatchContext":                  {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Batches":                             {reflect.TypeOf((**m2x.Batches)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BatchesContext":                      {reflect.TypeOf((**m2x.Batches)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BatchesPage":                         {reflect.TypeOf((**m2x.Batches)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BatchesPageContext":                  {reflect.TypeOf((**m2x.Batches)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Batch":                               {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"BatchContext":                        {reflect.TypeOf((**m2x.Batch)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBatch":                         {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBatchContext":                  {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBatchWithParams":               {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateBatchWithParamsContext":        {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateTrigger":                       {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateTriggerContext":                {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateTriggerWithParams":             {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateTriggerWithParamsContext":      {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteTrigger":                       {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteTriggerContext":                {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Triggers":                            {reflect.TypeOf((**m2x.Triggers)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"TriggersContext":                     {reflect.TypeOf((**m2x.Triggers)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Trigger":                             {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"TriggerContext":                      {reflect.TypeOf((**m2x.Trigger)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateTrigger":                       {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateTriggerContext":                {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateTriggerWithParams":             {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateTriggerWithParamsContext":      {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"TestTrigger":                         {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"TestTriggerContext":                  {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateKey":                           {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateKeyContext":                    {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateKeyWithParams":                 {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"CreateKeyWithParamsContext":          {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteKey":                           {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"DeleteKeyContext":                    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Keys":                                {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"KeysContext":                         {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"KeysPage":                            {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"KeysPageContext":                     {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"SearchKeys":                          {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"SearchKeysContext":                   {reflect.TypeOf((**m2x.Keys)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"Key":                                 {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"KeyContext":                          {reflect.TypeOf((**m2x.Key)(nil)).Elem(), reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateKey":                           {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateKeyContext":                    {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateKeyWithParams":                 {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+	"UpdateKeyWithParamsContext":          {reflect.TypeOf((**m2x.ErrorMessage)(nil)).Elem()},
+}
+
+// Feeds implements m2x.FeedsAPI
+func (m *Mock) Feeds() (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("Feeds")
+	if results == nil {
+		return nil, unscripted("Feeds")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedsContext implements m2x.FeedsAPI
+func (m *Mock) FeedsContext(ctx context.Context) (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("FeedsContext", ctx)
+	if results == nil {
+		return nil, unscripted("FeedsContext")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedsPage implements m2x.FeedsAPI
+func (m *Mock) FeedsPage(opts *m2x.ListOptions) (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("FeedsPage", opts)
+	if results == nil {
+		return nil, unscripted("FeedsPage")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedsPageContext implements m2x.FeedsAPI
+func (m *Mock) FeedsPageContext(ctx context.Context, opts *m2x.ListOptions) (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("FeedsPageContext", ctx, opts)
+	if results == nil {
+		return nil, unscripted("FeedsPageContext")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// SearchFeeds implements m2x.FeedsAPI
+func (m *Mock) SearchFeeds(query *m2x.FeedQuery) (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("SearchFeeds", query)
+	if results == nil {
+		return nil, unscripted("SearchFeeds")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// SearchFeedsContext implements m2x.FeedsAPI
+func (m *Mock) SearchFeedsContext(ctx context.Context, query *m2x.FeedQuery) (*m2x.Feeds, *m2x.ErrorMessage) {
+	results := m.called("SearchFeedsContext", ctx, query)
+	if results == nil {
+		return nil, unscripted("SearchFeedsContext")
+	}
+	r0, _ := results[0].(*m2x.Feeds)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Feed implements m2x.FeedsAPI
+func (m *Mock) Feed(resource string) (*m2x.Feed, *m2x.ErrorMessage) {
+	results := m.called("Feed", resource)
+	if results == nil {
+		return nil, unscripted("Feed")
+	}
+	r0, _ := results[0].(*m2x.Feed)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedContext implements m2x.FeedsAPI
+func (m *Mock) FeedContext(ctx context.Context, resource string) (*m2x.Feed, *m2x.ErrorMessage) {
+	results := m.called("FeedContext", ctx, resource)
+	if results == nil {
+		return nil, unscripted("FeedContext")
+	}
+	r0, _ := results[0].(*m2x.Feed)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedLocation implements m2x.FeedsAPI
+func (m *Mock) FeedLocation(resource string) (*m2x.Location, *m2x.ErrorMessage) {
+	results := m.called("FeedLocation", resource)
+	if results == nil {
+		return nil, unscripted("FeedLocation")
+	}
+	r0, _ := results[0].(*m2x.Location)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedLocationContext implements m2x.FeedsAPI
+func (m *Mock) FeedLocationContext(ctx context.Context, resource string) (*m2x.Location, *m2x.ErrorMessage) {
+	results := m.called("FeedLocationContext", ctx, resource)
+	if results == nil {
+		return nil, unscripted("FeedLocationContext")
+	}
+	r0, _ := results[0].(*m2x.Location)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateFeedLocation implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedLocation(resource string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedLocation", resource, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedLocation")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedLocationContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedLocationContext(ctx context.Context, resource string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedLocationContext", ctx, resource, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedLocationContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedLocationWithParams implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedLocationWithParams(resource string, params *m2x.LocationParams) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedLocationWithParams", resource, params)
+	if results == nil {
+		return unscripted("UpdateFeedLocationWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedLocationWithParamsContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedLocationWithParamsContext(ctx context.Context, resource string, params *m2x.LocationParams) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedLocationWithParamsContext", ctx, resource, params)
+	if results == nil {
+		return unscripted("UpdateFeedLocationWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// FeedStreams implements m2x.FeedsAPI
+func (m *Mock) FeedStreams(resource string) (*m2x.Streams, *m2x.ErrorMessage) {
+	results := m.called("FeedStreams", resource)
+	if results == nil {
+		return nil, unscripted("FeedStreams")
+	}
+	r0, _ := results[0].(*m2x.Streams)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedStreamsContext implements m2x.FeedsAPI
+func (m *Mock) FeedStreamsContext(ctx context.Context, resource string) (*m2x.Streams, *m2x.ErrorMessage) {
+	results := m.called("FeedStreamsContext", ctx, resource)
+	if results == nil {
+		return nil, unscripted("FeedStreamsContext")
+	}
+	r0, _ := results[0].(*m2x.Streams)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteFeedStream implements m2x.FeedsAPI
+func (m *Mock) DeleteFeedStream(resource string, name string) *m2x.ErrorMessage {
+	results := m.called("DeleteFeedStream", resource, name)
+	if results == nil {
+		return unscripted("DeleteFeedStream")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteFeedStreamContext implements m2x.FeedsAPI
+func (m *Mock) DeleteFeedStreamContext(ctx context.Context, resource string, name string) *m2x.ErrorMessage {
+	results := m.called("DeleteFeedStreamContext", ctx, resource, name)
+	if results == nil {
+		return unscripted("DeleteFeedStreamContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// FeedStream implements m2x.FeedsAPI
+func (m *Mock) FeedStream(resource string, name string) (*m2x.Stream, *m2x.ErrorMessage) {
+	results := m.called("FeedStream", resource, name)
+	if results == nil {
+		return nil, unscripted("FeedStream")
+	}
+	r0, _ := results[0].(*m2x.Stream)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedStreamContext implements m2x.FeedsAPI
+func (m *Mock) FeedStreamContext(ctx context.Context, resource string, name string) (*m2x.Stream, *m2x.ErrorMessage) {
+	results := m.called("FeedStreamContext", ctx, resource, name)
+	if results == nil {
+		return nil, unscripted("FeedStreamContext")
+	}
+	r0, _ := results[0].(*m2x.Stream)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateFeedStream implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStream(resource string, name string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStream", resource, name, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedStream")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedStreamContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStreamContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStreamContext", ctx, resource, name, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedStreamContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedStreamWithParams implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStreamWithParams(resource string, name string, params *m2x.StreamParams) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStreamWithParams", resource, name, params)
+	if results == nil {
+		return unscripted("UpdateFeedStreamWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedStreamWithParamsContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStreamWithParamsContext(ctx context.Context, resource string, name string, params *m2x.StreamParams) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStreamWithParamsContext", ctx, resource, name, params)
+	if results == nil {
+		return unscripted("UpdateFeedStreamWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// FeedStreamValues implements m2x.FeedsAPI
+func (m *Mock) FeedStreamValues(resource string, name string) (*m2x.Values, *m2x.ErrorMessage) {
+	results := m.called("FeedStreamValues", resource, name)
+	if results == nil {
+		return nil, unscripted("FeedStreamValues")
+	}
+	r0, _ := results[0].(*m2x.Values)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// FeedStreamValuesContext implements m2x.FeedsAPI
+func (m *Mock) FeedStreamValuesContext(ctx context.Context, resource string, name string) (*m2x.Values, *m2x.ErrorMessage) {
+	results := m.called("FeedStreamValuesContext", ctx, resource, name)
+	if results == nil {
+		return nil, unscripted("FeedStreamValuesContext")
+	}
+	r0, _ := results[0].(*m2x.Values)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// QueryFeedStreamValues implements m2x.FeedsAPI
+func (m *Mock) QueryFeedStreamValues(resource string, name string, query *m2x.ValuesQuery) (*m2x.Values, *m2x.ErrorMessage) {
+	results := m.called("QueryFeedStreamValues", resource, name, query)
+	if results == nil {
+		return nil, unscripted("QueryFeedStreamValues")
+	}
+	r0, _ := results[0].(*m2x.Values)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// QueryFeedStreamValuesContext implements m2x.FeedsAPI
+func (m *Mock) QueryFeedStreamValuesContext(ctx context.Context, resource string, name string, query *m2x.ValuesQuery) (*m2x.Values, *m2x.ErrorMessage) {
+	results := m.called("QueryFeedStreamValuesContext", ctx, resource, name, query)
+	if results == nil {
+		return nil, unscripted("QueryFeedStreamValuesContext")
+	}
+	r0, _ := results[0].(*m2x.Values)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateFeedStreamValues implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStreamValues(resource string, name string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStreamValues", resource, name, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedStreamValues")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedStreamValuesContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedStreamValuesContext(ctx context.Context, resource string, name string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedStreamValuesContext", ctx, resource, name, updateData)
+	if results == nil {
+		return unscripted("UpdateFeedStreamValuesContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteFeedStreamValues implements m2x.FeedsAPI
+func (m *Mock) DeleteFeedStreamValues(resource string, name string, valuesRange *m2x.ValuesRange) *m2x.ErrorMessage {
+	results := m.called("DeleteFeedStreamValues", resource, name, valuesRange)
+	if results == nil {
+		return unscripted("DeleteFeedStreamValues")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteFeedStreamValuesContext implements m2x.FeedsAPI
+func (m *Mock) DeleteFeedStreamValuesContext(ctx context.Context, resource string, name string, valuesRange *m2x.ValuesRange) *m2x.ErrorMessage {
+	results := m.called("DeleteFeedStreamValuesContext", ctx, resource, name, valuesRange)
+	if results == nil {
+		return unscripted("DeleteFeedStreamValuesContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// RequestLog implements m2x.FeedsAPI
+func (m *Mock) RequestLog(resource string) (*m2x.Requests, *m2x.ErrorMessage) {
+	results := m.called("RequestLog", resource)
+	if results == nil {
+		return nil, unscripted("RequestLog")
+	}
+	r0, _ := results[0].(*m2x.Requests)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// RequestLogContext implements m2x.FeedsAPI
+func (m *Mock) RequestLogContext(ctx context.Context, resource string) (*m2x.Requests, *m2x.ErrorMessage) {
+	results := m.called("RequestLogContext", ctx, resource)
+	if results == nil {
+		return nil, unscripted("RequestLogContext")
+	}
+	r0, _ := results[0].(*m2x.Requests)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateFeedValues implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedValues(resource string, values *m2x.FeedValues) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedValues", resource, values)
+	if results == nil {
+		return unscripted("UpdateFeedValues")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateFeedValuesContext implements m2x.FeedsAPI
+func (m *Mock) UpdateFeedValuesContext(ctx context.Context, resource string, values *m2x.FeedValues) *m2x.ErrorMessage {
+	results := m.called("UpdateFeedValuesContext", ctx, resource, values)
+	if results == nil {
+		return unscripted("UpdateFeedValuesContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// CreateBlueprint implements m2x.DatasourcesAPI
+func (m *Mock) CreateBlueprint(blueprint map[string]string) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("CreateBlueprint", blueprint)
+	if results == nil {
+		return nil, unscripted("CreateBlueprint")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBlueprintContext implements m2x.DatasourcesAPI
+func (m *Mock) CreateBlueprintContext(ctx context.Context, blueprint map[string]string) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("CreateBlueprintContext", ctx, blueprint)
+	if results == nil {
+		return nil, unscripted("CreateBlueprintContext")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBlueprintWithParams implements m2x.DatasourcesAPI
+func (m *Mock) CreateBlueprintWithParams(params *m2x.BlueprintParams) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("CreateBlueprintWithParams", params)
+	if results == nil {
+		return nil, unscripted("CreateBlueprintWithParams")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBlueprintWithParamsContext implements m2x.DatasourcesAPI
+func (m *Mock) CreateBlueprintWithParamsContext(ctx context.Context, params *m2x.BlueprintParams) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("CreateBlueprintWithParamsContext", ctx, params)
+	if results == nil {
+		return nil, unscripted("CreateBlueprintWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteBlueprint implements m2x.DatasourcesAPI
+func (m *Mock) DeleteBlueprint(id string) *m2x.ErrorMessage {
+	results := m.called("DeleteBlueprint", id)
+	if results == nil {
+		return unscripted("DeleteBlueprint")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteBlueprintContext implements m2x.DatasourcesAPI
+func (m *Mock) DeleteBlueprintContext(ctx context.Context, id string) *m2x.ErrorMessage {
+	results := m.called("DeleteBlueprintContext", ctx, id)
+	if results == nil {
+		return unscripted("DeleteBlueprintContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// Blueprints implements m2x.DatasourcesAPI
+func (m *Mock) Blueprints() (*m2x.Blueprints, *m2x.ErrorMessage) {
+	results := m.called("Blueprints")
+	if results == nil {
+		return nil, unscripted("Blueprints")
+	}
+	r0, _ := results[0].(*m2x.Blueprints)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BlueprintsContext implements m2x.DatasourcesAPI
+func (m *Mock) BlueprintsContext(ctx context.Context) (*m2x.Blueprints, *m2x.ErrorMessage) {
+	results := m.called("BlueprintsContext", ctx)
+	if results == nil {
+		return nil, unscripted("BlueprintsContext")
+	}
+	r0, _ := results[0].(*m2x.Blueprints)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BlueprintsPage implements m2x.DatasourcesAPI
+func (m *Mock) BlueprintsPage(opts *m2x.ListOptions) (*m2x.Blueprints, *m2x.ErrorMessage) {
+	results := m.called("BlueprintsPage", opts)
+	if results == nil {
+		return nil, unscripted("BlueprintsPage")
+	}
+	r0, _ := results[0].(*m2x.Blueprints)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BlueprintsPageContext implements m2x.DatasourcesAPI
+func (m *Mock) BlueprintsPageContext(ctx context.Context, opts *m2x.ListOptions) (*m2x.Blueprints, *m2x.ErrorMessage) {
+	results := m.called("BlueprintsPageContext", ctx, opts)
+	if results == nil {
+		return nil, unscripted("BlueprintsPageContext")
+	}
+	r0, _ := results[0].(*m2x.Blueprints)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Blueprint implements m2x.DatasourcesAPI
+func (m *Mock) Blueprint(id string) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("Blueprint", id)
+	if results == nil {
+		return nil, unscripted("Blueprint")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BlueprintContext implements m2x.DatasourcesAPI
+func (m *Mock) BlueprintContext(ctx context.Context, id string) (*m2x.Blueprint, *m2x.ErrorMessage) {
+	results := m.called("BlueprintContext", ctx, id)
+	if results == nil {
+		return nil, unscripted("BlueprintContext")
+	}
+	r0, _ := results[0].(*m2x.Blueprint)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateBlueprint implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBlueprint(id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateBlueprint", id, updateData)
+	if results == nil {
+		return unscripted("UpdateBlueprint")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBlueprintContext implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBlueprintContext(ctx context.Context, id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateBlueprintContext", ctx, id, updateData)
+	if results == nil {
+		return unscripted("UpdateBlueprintContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBlueprintWithParams implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBlueprintWithParams(id string, params *m2x.BlueprintParams) *m2x.ErrorMessage {
+	results := m.called("UpdateBlueprintWithParams", id, params)
+	if results == nil {
+		return unscripted("UpdateBlueprintWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBlueprintWithParamsContext implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBlueprintWithParamsContext(ctx context.Context, id string, params *m2x.BlueprintParams) *m2x.ErrorMessage {
+	results := m.called("UpdateBlueprintWithParamsContext", ctx, id, params)
+	if results == nil {
+		return unscripted("UpdateBlueprintWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// CreateBatch implements m2x.DatasourcesAPI
+func (m *Mock) CreateBatch(batch map[string]string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("CreateBatch", batch)
+	if results == nil {
+		return nil, unscripted("CreateBatch")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBatchContext implements m2x.DatasourcesAPI
+func (m *Mock) CreateBatchContext(ctx context.Context, batch map[string]string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("CreateBatchContext", ctx, batch)
+	if results == nil {
+		return nil, unscripted("CreateBatchContext")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBatchWithParams implements m2x.DatasourcesAPI
+func (m *Mock) CreateBatchWithParams(params *m2x.BatchParams) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("CreateBatchWithParams", params)
+	if results == nil {
+		return nil, unscripted("CreateBatchWithParams")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateBatchWithParamsContext implements m2x.DatasourcesAPI
+func (m *Mock) CreateBatchWithParamsContext(ctx context.Context, params *m2x.BatchParams) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("CreateBatchWithParamsContext", ctx, params)
+	if results == nil {
+		return nil, unscripted("CreateBatchWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteBatch implements m2x.DatasourcesAPI
+func (m *Mock) DeleteBatch(id string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("DeleteBatch", id)
+	if results == nil {
+		return nil, unscripted("DeleteBatch")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteBatchContext implements m2x.DatasourcesAPI
+func (m *Mock) DeleteBatchContext(ctx context.Context, id string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("DeleteBatchContext", ctx, id)
+	if results == nil {
+		return nil, unscripted("DeleteBatchContext")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Batches implements m2x.DatasourcesAPI
+func (m *Mock) Batches() (*m2x.Batches, *m2x.ErrorMessage) {
+	results := m.called("Batches")
+	if results == nil {
+		return nil, unscripted("Batches")
+	}
+	r0, _ := results[0].(*m2x.Batches)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BatchesContext implements m2x.DatasourcesAPI
+func (m *Mock) BatchesContext(ctx context.Context) (*m2x.Batches, *m2x.ErrorMessage) {
+	results := m.called("BatchesContext", ctx)
+	if results == nil {
+		return nil, unscripted("BatchesContext")
+	}
+	r0, _ := results[0].(*m2x.Batches)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BatchesPage implements m2x.DatasourcesAPI
+func (m *Mock) BatchesPage(opts *m2x.ListOptions) (*m2x.Batches, *m2x.ErrorMessage) {
+	results := m.called("BatchesPage", opts)
+	if results == nil {
+		return nil, unscripted("BatchesPage")
+	}
+	r0, _ := results[0].(*m2x.Batches)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BatchesPageContext implements m2x.DatasourcesAPI
+func (m *Mock) BatchesPageContext(ctx context.Context, opts *m2x.ListOptions) (*m2x.Batches, *m2x.ErrorMessage) {
+	results := m.called("BatchesPageContext", ctx, opts)
+	if results == nil {
+		return nil, unscripted("BatchesPageContext")
+	}
+	r0, _ := results[0].(*m2x.Batches)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Batch implements m2x.DatasourcesAPI
+func (m *Mock) Batch(id string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("Batch", id)
+	if results == nil {
+		return nil, unscripted("Batch")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// BatchContext implements m2x.DatasourcesAPI
+func (m *Mock) BatchContext(ctx context.Context, id string) (*m2x.Batch, *m2x.ErrorMessage) {
+	results := m.called("BatchContext", ctx, id)
+	if results == nil {
+		return nil, unscripted("BatchContext")
+	}
+	r0, _ := results[0].(*m2x.Batch)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateBatch implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBatch(id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateBatch", id, updateData)
+	if results == nil {
+		return unscripted("UpdateBatch")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBatchContext implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBatchContext(ctx context.Context, id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateBatchContext", ctx, id, updateData)
+	if results == nil {
+		return unscripted("UpdateBatchContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBatchWithParams implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBatchWithParams(id string, params *m2x.BatchParams) *m2x.ErrorMessage {
+	results := m.called("UpdateBatchWithParams", id, params)
+	if results == nil {
+		return unscripted("UpdateBatchWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateBatchWithParamsContext implements m2x.DatasourcesAPI
+func (m *Mock) UpdateBatchWithParamsContext(ctx context.Context, id string, params *m2x.BatchParams) *m2x.ErrorMessage {
+	results := m.called("UpdateBatchWithParamsContext", ctx, id, params)
+	if results == nil {
+		return unscripted("UpdateBatchWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// CreateTrigger implements m2x.TriggersAPI
+func (m *Mock) CreateTrigger(resource string, trigger map[string]string) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("CreateTrigger", resource, trigger)
+	if results == nil {
+		return nil, unscripted("CreateTrigger")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateTriggerContext implements m2x.TriggersAPI
+func (m *Mock) CreateTriggerContext(ctx context.Context, resource string, trigger map[string]string) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("CreateTriggerContext", ctx, resource, trigger)
+	if results == nil {
+		return nil, unscripted("CreateTriggerContext")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateTriggerWithParams implements m2x.TriggersAPI
+func (m *Mock) CreateTriggerWithParams(resource string, params *m2x.TriggerParams) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("CreateTriggerWithParams", resource, params)
+	if results == nil {
+		return nil, unscripted("CreateTriggerWithParams")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateTriggerWithParamsContext implements m2x.TriggersAPI
+func (m *Mock) CreateTriggerWithParamsContext(ctx context.Context, resource string, params *m2x.TriggerParams) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("CreateTriggerWithParamsContext", ctx, resource, params)
+	if results == nil {
+		return nil, unscripted("CreateTriggerWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteTrigger implements m2x.TriggersAPI
+func (m *Mock) DeleteTrigger(resource string, id string) *m2x.ErrorMessage {
+	results := m.called("DeleteTrigger", resource, id)
+	if results == nil {
+		return unscripted("DeleteTrigger")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteTriggerContext implements m2x.TriggersAPI
+func (m *Mock) DeleteTriggerContext(ctx context.Context, resource string, id string) *m2x.ErrorMessage {
+	results := m.called("DeleteTriggerContext", ctx, resource, id)
+	if results == nil {
+		return unscripted("DeleteTriggerContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// Triggers implements m2x.TriggersAPI
+func (m *Mock) Triggers(resource string) (*m2x.Triggers, *m2x.ErrorMessage) {
+	results := m.called("Triggers", resource)
+	if results == nil {
+		return nil, unscripted("Triggers")
+	}
+	r0, _ := results[0].(*m2x.Triggers)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// TriggersContext implements m2x.TriggersAPI
+func (m *Mock) TriggersContext(ctx context.Context, resource string) (*m2x.Triggers, *m2x.ErrorMessage) {
+	results := m.called("TriggersContext", ctx, resource)
+	if results == nil {
+		return nil, unscripted("TriggersContext")
+	}
+	r0, _ := results[0].(*m2x.Triggers)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Trigger implements m2x.TriggersAPI
+func (m *Mock) Trigger(resource string, id string) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("Trigger", resource, id)
+	if results == nil {
+		return nil, unscripted("Trigger")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// TriggerContext implements m2x.TriggersAPI
+func (m *Mock) TriggerContext(ctx context.Context, resource string, id string) (*m2x.Trigger, *m2x.ErrorMessage) {
+	results := m.called("TriggerContext", ctx, resource, id)
+	if results == nil {
+		return nil, unscripted("TriggerContext")
+	}
+	r0, _ := results[0].(*m2x.Trigger)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateTrigger implements m2x.TriggersAPI
+func (m *Mock) UpdateTrigger(resource string, id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateTrigger", resource, id, updateData)
+	if results == nil {
+		return unscripted("UpdateTrigger")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateTriggerContext implements m2x.TriggersAPI
+func (m *Mock) UpdateTriggerContext(ctx context.Context, resource string, id string, updateData map[string]string) *m2x.ErrorMessage {
+	results := m.called("UpdateTriggerContext", ctx, resource, id, updateData)
+	if results == nil {
+		return unscripted("UpdateTriggerContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateTriggerWithParams implements m2x.TriggersAPI
+func (m *Mock) UpdateTriggerWithParams(resource string, id string, params *m2x.TriggerParams) *m2x.ErrorMessage {
+	results := m.called("UpdateTriggerWithParams", resource, id, params)
+	if results == nil {
+		return unscripted("UpdateTriggerWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateTriggerWithParamsContext implements m2x.TriggersAPI
+func (m *Mock) UpdateTriggerWithParamsContext(ctx context.Context, resource string, id string, params *m2x.TriggerParams) *m2x.ErrorMessage {
+	results := m.called("UpdateTriggerWithParamsContext", ctx, resource, id, params)
+	if results == nil {
+		return unscripted("UpdateTriggerWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// TestTrigger implements m2x.TriggersAPI
+func (m *Mock) TestTrigger(resource string, name string) *m2x.ErrorMessage {
+	results := m.called("TestTrigger", resource, name)
+	if results == nil {
+		return unscripted("TestTrigger")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// TestTriggerContext implements m2x.TriggersAPI
+func (m *Mock) TestTriggerContext(ctx context.Context, resource string, name string) *m2x.ErrorMessage {
+	results := m.called("TestTriggerContext", ctx, resource, name)
+	if results == nil {
+		return unscripted("TestTriggerContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// CreateKey implements m2x.KeysAPI
+func (m *Mock) CreateKey(key map[string]interface{}) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("CreateKey", key)
+	if results == nil {
+		return nil, unscripted("CreateKey")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateKeyContext implements m2x.KeysAPI
+func (m *Mock) CreateKeyContext(ctx context.Context, key map[string]interface{}) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("CreateKeyContext", ctx, key)
+	if results == nil {
+		return nil, unscripted("CreateKeyContext")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateKeyWithParams implements m2x.KeysAPI
+func (m *Mock) CreateKeyWithParams(params *m2x.KeyParams) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("CreateKeyWithParams", params)
+	if results == nil {
+		return nil, unscripted("CreateKeyWithParams")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// CreateKeyWithParamsContext implements m2x.KeysAPI
+func (m *Mock) CreateKeyWithParamsContext(ctx context.Context, params *m2x.KeyParams) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("CreateKeyWithParamsContext", ctx, params)
+	if results == nil {
+		return nil, unscripted("CreateKeyWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// DeleteKey implements m2x.KeysAPI
+func (m *Mock) DeleteKey(id string) *m2x.ErrorMessage {
+	results := m.called("DeleteKey", id)
+	if results == nil {
+		return unscripted("DeleteKey")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// DeleteKeyContext implements m2x.KeysAPI
+func (m *Mock) DeleteKeyContext(ctx context.Context, id string) *m2x.ErrorMessage {
+	results := m.called("DeleteKeyContext", ctx, id)
+	if results == nil {
+		return unscripted("DeleteKeyContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// Keys implements m2x.KeysAPI
+func (m *Mock) Keys() (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("Keys")
+	if results == nil {
+		return nil, unscripted("Keys")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// KeysContext implements m2x.KeysAPI
+func (m *Mock) KeysContext(ctx context.Context) (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("KeysContext", ctx)
+	if results == nil {
+		return nil, unscripted("KeysContext")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// KeysPage implements m2x.KeysAPI
+func (m *Mock) KeysPage(opts *m2x.ListOptions) (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("KeysPage", opts)
+	if results == nil {
+		return nil, unscripted("KeysPage")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// KeysPageContext implements m2x.KeysAPI
+func (m *Mock) KeysPageContext(ctx context.Context, opts *m2x.ListOptions) (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("KeysPageContext", ctx, opts)
+	if results == nil {
+		return nil, unscripted("KeysPageContext")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// SearchKeys implements m2x.KeysAPI
+func (m *Mock) SearchKeys(query *m2x.KeyQuery) (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("SearchKeys", query)
+	if results == nil {
+		return nil, unscripted("SearchKeys")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// SearchKeysContext implements m2x.KeysAPI
+func (m *Mock) SearchKeysContext(ctx context.Context, query *m2x.KeyQuery) (*m2x.Keys, *m2x.ErrorMessage) {
+	results := m.called("SearchKeysContext", ctx, query)
+	if results == nil {
+		return nil, unscripted("SearchKeysContext")
+	}
+	r0, _ := results[0].(*m2x.Keys)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// Key implements m2x.KeysAPI
+func (m *Mock) Key(id string) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("Key", id)
+	if results == nil {
+		return nil, unscripted("Key")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// KeyContext implements m2x.KeysAPI
+func (m *Mock) KeyContext(ctx context.Context, id string) (*m2x.Key, *m2x.ErrorMessage) {
+	results := m.called("KeyContext", ctx, id)
+	if results == nil {
+		return nil, unscripted("KeyContext")
+	}
+	r0, _ := results[0].(*m2x.Key)
+	r1, _ := results[1].(*m2x.ErrorMessage)
+	return r0, r1
+}
+
+// UpdateKey implements m2x.KeysAPI
+func (m *Mock) UpdateKey(id string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateKey", id, updateData)
+	if results == nil {
+		return unscripted("UpdateKey")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateKeyContext implements m2x.KeysAPI
+func (m *Mock) UpdateKeyContext(ctx context.Context, id string, updateData map[string]interface{}) *m2x.ErrorMessage {
+	results := m.called("UpdateKeyContext", ctx, id, updateData)
+	if results == nil {
+		return unscripted("UpdateKeyContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateKeyWithParams implements m2x.KeysAPI
+func (m *Mock) UpdateKeyWithParams(id string, params *m2x.KeyParams) *m2x.ErrorMessage {
+	results := m.called("UpdateKeyWithParams", id, params)
+	if results == nil {
+		return unscripted("UpdateKeyWithParams")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
+
+// UpdateKeyWithParamsContext implements m2x.KeysAPI
+func (m *Mock) UpdateKeyWithParamsContext(ctx context.Context, id string, params *m2x.KeyParams) *m2x.ErrorMessage {
+	results := m.called("UpdateKeyWithParamsContext", ctx, id, params)
+	if results == nil {
+		return unscripted("UpdateKeyWithParamsContext")
+	}
+	r0, _ := results[0].(*m2x.ErrorMessage)
+	return r0
+}
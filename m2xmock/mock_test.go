@@ -0,0 +1,121 @@
+// Copyright (c) 2014 Jason Goecke
+// mock_test.go
+
+package m2xmock_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	m2x "github.com/jsgoecke/m2x-go"
+	"github.com/jsgoecke/m2x-go/m2xmock"
+)
+
+// Disables the triggers of the streams of a feed, as code under test would
+func disableTriggers(api m2x.API, resource string) *m2x.ErrorMessage {
+	triggers, errorMessage := api.Triggers(resource)
+	if errorMessage != nil {
+		return errorMessage
+	}
+	for _, trigger := range triggers.Triggers {
+		if errorMessage := api.UpdateTriggerWithParamsContext(context.Background(), resource, trigger.ID, &m2x.TriggerParams{Status: "disabled"}); errorMessage != nil {
+			return errorMessage
+		}
+	}
+	return nil
+}
+
+func TestMock(t *testing.T) {
+	mock := &m2xmock.Mock{}
+	mock.Return("Triggers", &m2x.Triggers{Triggers: []m2x.Trigger{{ID: "1"}, {ID: "2"}}}, nil)
+	mock.Return("UpdateTriggerWithParamsContext", nil)
+	mock.Return("UpdateTriggerWithParamsContext", &m2x.ErrorMessage{Message: "Not found", StatusCode: 404})
+
+	if errorMessage := disableTriggers(mock, "/feeds/1234"); !m2x.IsNotFound(errorMessage) {
+		t.Errorf("The scripted error was not returned: %v", errorMessage)
+	}
+	calls := mock.Calls()
+	if len(calls) != 3 || calls[0].Method != "Triggers" || calls[0].Args[0] != "/feeds/1234" {
+		t.Fatalf("The calls were not recorded properly: %+v", calls)
+	}
+	updates := mock.CallsTo("UpdateTriggerWithParamsContext")
+	if len(updates) != 2 || updates[1].Args[2] != "2" || updates[1].Args[3].(*m2x.TriggerParams).Status != "disabled" {
+		t.Errorf("The updates were not recorded properly: %+v", updates)
+	}
+
+	errorMessage := disableTriggers(mock, "/feeds/1234")
+	if errorMessage == nil || !strings.Contains(errorMessage.Message, "no results scripted for Triggers") {
+		t.Errorf("A call without scripted results should fail: %v", errorMessage)
+	}
+
+	mock.Reset()
+	mock.ReturnAlways("Feed", &m2x.Feed{ID: "1234"}, nil)
+	for i := 0; i < 2; i++ {
+		if feed, errorMessage := mock.Feed("/feeds/1234"); errorMessage != nil || feed.ID != "1234" {
+			t.Errorf("The results should have been returned on every call: %v", errorMessage)
+		}
+	}
+	if len(mock.Calls()) != 2 {
+		t.Errorf("The calls should have been reset")
+	}
+}
+
+func TestMockReturnMismatch(t *testing.T) {
+	for _, test := range []struct {
+		method  string
+		results []interface{}
+		panic   string
+	}{
+		{"Fed", []interface{}{nil, nil}, "unknown method Fed"},
+		{"Feed", []interface{}{nil}, "Feed returns 2 results, got 1"},
+		{"Feed", []interface{}{&m2x.Feeds{}, nil}, "result 1 of Feed is a *m2x.Feed, got *m2x.Feeds"},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(r.(string), test.panic) {
+					t.Errorf("Expected a panic with %q, got %v", test.panic, r)
+				}
+			}()
+			(&m2xmock.Mock{}).Return(test.method, test.results...)
+		}()
+	}
+}
+
+func TestMockWalkValues(t *testing.T) {
+	mock := &m2xmock.Mock{}
+	walk := func() ([]string, *m2x.ErrorMessage) {
+		var walked []string
+		errorMessage := mock.WalkFeedStreamValues(context.Background(), "/feeds/1234", "temperature", nil, func(value m2x.Value) error {
+			walked = append(walked, value.Value.String())
+			if value.Value.String() == "stop" {
+				return errors.New("stopped")
+			}
+			return nil
+		})
+		return walked, errorMessage
+	}
+	if walked, errorMessage := walk(); len(walked) != 0 || errorMessage == nil {
+		t.Errorf("A walk without scripted values should fail: %v %v", walked, errorMessage)
+	}
+
+	mock.WalkValues(m2x.Value{Value: m2x.Float64Value(21.5)}, m2x.Value{Value: m2x.Float64Value(20)})
+	for i := 0; i < 2; i++ {
+		if walked, errorMessage := walk(); strings.Join(walked, ",") != "21.5,20" || errorMessage != nil {
+			t.Errorf("The scripted values were not walked: %v %v", walked, errorMessage)
+		}
+	}
+	mock.Return("WalkFeedStreamValues", &m2x.ErrorMessage{Message: "Not found", StatusCode: 404})
+	if walked, errorMessage := walk(); len(walked) != 2 || !m2x.IsNotFound(errorMessage) {
+		t.Errorf("The scripted error should be returned once the values were walked: %v %v", walked, errorMessage)
+	}
+
+	mock.WalkValues(m2x.Value{Value: m2x.StringValue("stop")}, m2x.Value{Value: m2x.Float64Value(20)})
+	if walked, errorMessage := walk(); len(walked) != 1 || errorMessage == nil || errorMessage.Err == nil || errorMessage.Err.Error() != "stopped" {
+		t.Errorf("The walk should stop at the error of the function: %v %v", walked, errorMessage)
+	}
+	if calls := mock.CallsTo("WalkFeedStreamValues"); len(calls) != 5 || calls[0].Args[2] != "temperature" {
+		t.Errorf("The walks were not recorded properly: %+v", calls)
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2014 Jason Goecke
+// cassette.go
+
+package m2xtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Cassette records or replays requests
+type Mode int
+
+const (
+	// Replay serves the responses saved in the cassette file without any
+	// network access
+	Replay Mode = iota
+	// Record sends requests through a real transport and keeps them, along
+	// with their responses, until the cassette is saved
+	Record
+)
+
+// Cassette is an http.RoundTripper recording requests made to the M2X API into
+// a fixture file, or replaying them from it. When saved, the API key sent in
+// X-M2X-KEY, the credentials sent in the Authorization, Proxy-Authorization
+// and Cookie headers, and every "key" field found in the bodies are replaced
+// with placeholders wherever they appear, so fixtures can be committed safely.
+//
+// On replay, each request is answered with the first response not yet used
+// that was recorded for the same method, path and query. Hosts, headers and
+// bodies are not compared, so requests with timestamps in their bodies still
+// match, and requests repeated in the same order get the same responses.
+//
+//		cassette, err := m2xtest.NewCassette("testdata/blueprints.json", m2xtest.Replay, nil)
+//		client := m2x.NewClient("<API-KEY>", m2x.WithTransport(cassette))
+type Cassette struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	secrets      []string
+}
+
+// Interaction is a request and its response, as saved in a cassette file
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request saved in a cassette file
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// RecordedResponse is a response saved in a cassette file
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Headers left out of recorded responses, as they change on every request or
+// no longer hold once the body is redacted
+var volatileHeaders = []string{"Content-Length", "Date", "Set-Cookie"}
+
+// Request headers carrying credentials, whose values are redacted wherever they
+// appear when the cassette is saved
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-M2X-KEY"}
+
+// NewCassette creates a cassette backed by the file at path. In Record mode
+// requests are sent through transport, or http.DefaultTransport if nil, and
+// the file is written by Save. In Replay mode the file is read and transport
+// is not used. The file holds the array of interactions written by Save, or
+// an object with the "interactions" along with a "comment" describing them.
+func NewCassette(path string, mode Mode, transport http.RoundTripper) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode, transport: transport}
+	if c.transport == nil {
+		c.transport = http.DefaultTransport
+	}
+	if mode == Record {
+		return c, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var file struct {
+			Comment      string         `json:"comment"`
+			Interactions []*Interaction `json:"interactions"`
+		}
+		err = json.Unmarshal(data, &file)
+		c.interactions = file.Interactions
+	} else {
+		err = json.Unmarshal(data, &c.interactions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("m2xtest: reading cassette %s: %v", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// Redact makes Save replace secret wherever it appears, in addition to the
+// credentials sent in headers and the keys found in bodies
+func (c *Cassette) Redact(secret string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addSecret(secret)
+}
+
+// Interactions returns the requests recorded or replayed by the cassette
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Unused returns the interactions of a replayed cassette that have not answered
+// any request yet. Tests may check it is empty once done, so a cassette left
+// stale by a test no longer making some of its requests is caught.
+//
+//		if unused := cassette.Unused(); len(unused) != 0 {
+//			t.Errorf("%d recorded requests were not replayed", len(unused))
+//		}
+func (c *Cassette) Unused() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var unused []*Interaction
+	for i, interaction := range c.interactions {
+		if i < len(c.used) && !c.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// RoundTrip records or replays a request, depending on the mode of the cassette
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.mode == Replay {
+		return c.replay(req)
+	}
+	return c.record(req)
+}
+
+// Save redacts the recorded interactions and writes them to the cassette file
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, interaction := range c.interactions {
+		c.findSecrets(interaction.Request.Body)
+		c.findSecrets(interaction.Response.Body)
+	}
+	replacements := c.replacements()
+	redacted := make([]*Interaction, len(c.interactions))
+	for i, interaction := range c.interactions {
+		copied := *interaction
+		copied.Request.URL = replacements.Replace(copied.Request.URL)
+		copied.Request.Body = replacements.Replace(copied.Request.Body)
+		copied.Request.Header = redactHeader(copied.Request.Header, replacements)
+		copied.Response.Body = replacements.Replace(copied.Response.Body)
+		copied.Response.Header = redactHeader(copied.Response.Header, replacements)
+		redacted[i] = &copied
+	}
+	data, err := json.MarshalIndent(redacted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0644)
+}
+
+// Sends a request through the transport and keeps it along with its response.
+// The body is read from a copy returned by GetBody when the request has one,
+// otherwise the request is consumed and a clone carrying the body is sent, so
+// the caller's request is never modified.
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	var body []byte
+	sent := req
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		copied := req.Body
+		if req.GetBody != nil {
+			if copied, err = req.GetBody(); err != nil {
+				req.Body.Close()
+				return nil, err
+			}
+		}
+		body, err = ioutil.ReadAll(copied)
+		copied.Close()
+		if err != nil {
+			return nil, err
+		}
+		if req.GetBody == nil {
+			sent = req.Clone(req.Context())
+			sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+	}
+	resp, err := c.transport.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, name := range volatileHeaders {
+		header.Del(name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range sensitiveHeaders {
+		for _, value := range req.Header.Values(name) {
+			c.addSecret(value)
+		}
+	}
+	c.interactions = append(c.interactions, &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(respBody),
+		},
+	})
+	return resp, nil
+}
+
+// Answers a request with the first unused response recorded for it
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.interactions {
+		if c.used[i] || interaction.Request.Method != req.Method || interaction.Request.URL != req.URL.RequestURI() {
+			continue
+		}
+		c.used[i] = true
+		return &http.Response{
+			Status:        strconv.Itoa(interaction.Response.StatusCode) + " " + http.StatusText(interaction.Response.StatusCode),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("m2xtest: cassette %s has no recorded response left for %s %s", c.path, req.Method, req.URL.RequestURI())
+}
+
+// Adds a value to redact. Must be called with c.mu held.
+func (c *Cassette) addSecret(secret string) {
+	if secret == "" || strings.HasPrefix(secret, "REDACTED-") {
+		return
+	}
+	for _, s := range c.secrets {
+		if s == secret {
+			return
+		}
+	}
+	c.secrets = append(c.secrets, secret)
+}
+
+// Adds the "key" fields of a JSON body to the values to redact. Must be
+// called with c.mu held.
+func (c *Cassette) findSecrets(body string) {
+	var v interface{}
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if key, ok := v["key"].(string); ok {
+				c.addSecret(key)
+			}
+			for _, field := range v {
+				walk(field)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+}
+
+// Returns a replacer swapping each secret for a placeholder numbered in the
+// order the secrets were found, longest secrets first so none is left
+// partially replaced. Must be called with c.mu held.
+func (c *Cassette) replacements() *strings.Replacer {
+	placeholders := make(map[string]string, len(c.secrets))
+	for i, secret := range c.secrets {
+		placeholders[secret] = "REDACTED-KEY-" + strconv.Itoa(i+1)
+	}
+	secrets := append([]string(nil), c.secrets...)
+	sort.SliceStable(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	var pairs []string
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, placeholders[secret])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Returns a copy of header with the secrets replaced
+func redactHeader(header http.Header, replacements *strings.Replacer) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			redacted.Add(name, replacements.Replace(value))
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2014 Jason Goecke
+// cassette_test.go
+
+package m2xtest_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	m2x "github.com/jsgoecke/m2x-go"
+	"github.com/jsgoecke/m2x-go/m2xtest"
+)
+
+// Creates, updates and deletes a blueprint with a trigger, returning the
+// blueprint as first created
+func blueprintLifecycle(t *testing.T, client *m2x.Client) *m2x.Blueprint {
+	name := "Go Created Blueprint - " + time.Now().Format("20060102150405.000000")
+	blueprint, errorMessage := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: name, Visibility: "private"})
+	if errorMessage != nil {
+		t.Fatalf("Did not create the blueprint properly: %v", errorMessage)
+	}
+	client.UpdateFeedStreamWithParams(blueprint.Feed, "temperature", &m2x.StreamParams{})
+	trigger, errorMessage := client.CreateTriggerWithParams(blueprint.Feed, &m2x.TriggerParams{
+		Name:        "Too hot",
+		Stream:      "temperature",
+		Condition:   ">",
+		Value:       "30",
+		CallbackURL: "http://example.com/hook",
+	})
+	if errorMessage != nil {
+		t.Fatalf("Did not create the trigger properly: %v", errorMessage)
+	}
+	if keys, _ := client.SearchKeys(&m2x.KeyQuery{Feed: blueprint.Feed}); len(keys.Keys) != 1 || keys.Keys[0].Key != blueprint.Key {
+		t.Errorf("Did not list the key of the blueprint properly")
+	}
+	if errorMessage := client.DeleteTrigger(blueprint.Feed, trigger.ID); errorMessage != nil {
+		t.Errorf("Did not delete the trigger properly: %v", errorMessage)
+	}
+	if errorMessage := client.DeleteBlueprint(blueprint.ID); errorMessage != nil {
+		t.Errorf("Did not delete the blueprint properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.Blueprint(blueprint.ID); !m2x.IsNotFound(errorMessage) {
+		t.Errorf("The blueprint should have been deleted: %v", errorMessage)
+	}
+	return blueprint
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := m2xtest.NewServer()
+	path := filepath.Join(t.TempDir(), "cassettes", "blueprint.json")
+
+	recorder, err := m2xtest.NewCassette(path, m2xtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := m2x.NewClient(server.APIKey, m2x.WithTransport(recorder))
+	client.APIBase = server.URL
+	recorded := blueprintLifecycle(t, client)
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{server.APIKey, recorded.Key} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("The cassette should not contain the key %s", secret)
+		}
+	}
+
+	// Replay without a server, with requests sent to a different host
+	replayer, err := m2xtest.NewCassette(path, m2xtest.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := replayer.Interactions()[0].Request.Header.Get("X-M2X-KEY"); key != "REDACTED-KEY-1" {
+		t.Errorf("The API key header should have been redacted, got %s", key)
+	}
+	if len(replayer.Unused()) != 7 {
+		t.Errorf("Expected 7 interactions left to replay, got %d", len(replayer.Unused()))
+	}
+	client = m2x.NewClient("1234", m2x.WithTransport(replayer))
+	client.APIBase = "http://api-m2x.example.com"
+	replayed := blueprintLifecycle(t, client)
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Every interaction should have been replayed, %d were not", len(unused))
+	}
+	if replayed.ID != recorded.ID || replayed.Key == recorded.Key || !strings.HasPrefix(replayed.Key, "REDACTED-KEY-") {
+		t.Errorf("The recorded responses were not replayed properly: %+v", replayed)
+	}
+	if _, errorMessage := client.Blueprints(); errorMessage == nil || !strings.Contains(errorMessage.Error(), "no recorded response") {
+		t.Errorf("A request that was not recorded should fail: %v", errorMessage)
+	}
+}
+
+func TestCassetteFixture(t *testing.T) {
+	// Records the fixture again against the live API
+	if apiKey := os.Getenv("M2X_API_KEY"); apiKey != "" && os.Getenv("M2X_RECORD") != "" {
+		recorder, err := m2xtest.NewCassette("testdata/blueprint.json", m2xtest.Record, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blueprintLifecycle(t, m2x.NewClient(apiKey, m2x.WithTransport(recorder)))
+		if err := recorder.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Replays the synthetic fixture, written by hand, until one is recorded
+	path := "testdata/blueprint.json"
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		path = "testdata/blueprint_synthetic.json"
+	}
+	cassette, err := m2xtest.NewCassette(path, m2xtest.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := m2x.NewClient("1234", m2x.WithTransport(cassette))
+	blueprint := blueprintLifecycle(t, client)
+	if blueprint.Feed != "/feeds/"+blueprint.ID || blueprint.Created.IsZero() {
+		t.Errorf("The fixture was not replayed properly: %+v", blueprint)
+	}
+	if len(cassette.Interactions()) != 7 {
+		t.Errorf("Expected 7 recorded interactions, got %d", len(cassette.Interactions()))
+	}
+	if unused := cassette.Unused(); len(unused) != 0 {
+		t.Errorf("Every interaction should have been replayed, %d were not", len(unused))
+	}
+}
+
+func TestCassetteRecordLeavesRequestAlone(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	recorder, err := m2xtest.NewCassette(filepath.Join(t.TempDir(), "cassette.json"), m2xtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withGetBody, _ := http.NewRequest("POST", server.URL+"/v1/feeds", strings.NewReader(`{"name":"a"}`))
+	withoutGetBody, _ := http.NewRequest("POST", server.URL+"/v1/feeds", ioutil.NopCloser(strings.NewReader(`{"name":"b"}`)))
+	for _, req := range []*http.Request{withGetBody, withoutGetBody} {
+		body := req.Body
+		resp, err := recorder.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if req.Body != body {
+			t.Errorf("The body of the request should not have been replaced")
+		}
+	}
+
+	interactions := recorder.Interactions()
+	if strings.Join(received, ",") != `{"name":"a"},{"name":"b"}` || interactions[0].Request.Body != `{"name":"a"}` || interactions[1].Request.Body != `{"name":"b"}` {
+		t.Errorf("The bodies were not sent and recorded properly: %v %+v %+v", received, interactions[0].Request, interactions[1].Request)
+	}
+}
+
+// closeRecorder is a request body reporting whether it was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestCassetteRecordClosesBodyWhenGetBodyFails(t *testing.T) {
+	recorder, err := m2xtest.NewCassette(filepath.Join(t.TempDir(), "cassette.json"), m2xtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := &closeRecorder{Reader: strings.NewReader(`{"name":"a"}`)}
+	req, _ := http.NewRequest("POST", "http://api-m2x.example.com/v1/feeds", body)
+	req.GetBody = func() (io.ReadCloser, error) {
+		return nil, errors.New("body already consumed")
+	}
+	if _, err := recorder.RoundTrip(req); err == nil || !body.closed {
+		t.Errorf("The body should have been closed when GetBody failed: %v", err)
+	}
+}
+
+func TestCassetteRedactsCredentialHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := m2xtest.NewCassette(path, m2xtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", server.URL+"/v1/feeds", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Cookie", "session=secret-session")
+	req.Header.Set("Proxy-Authorization", "Basic secret-proxy")
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "secret-") {
+		t.Errorf("The cassette should not contain credentials: %s", data)
+	}
+	replayer, err := m2xtest.NewCassette(path, m2xtest.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := replayer.Interactions()[0].Request.Header; !strings.HasPrefix(header.Get("Authorization"), "REDACTED-KEY-") || !strings.HasPrefix(header.Get("Cookie"), "REDACTED-KEY-") {
+		t.Errorf("The credential headers should have been redacted: %v", header)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2014 Jason Goecke
+// datasources.go
+
+package m2xtest
+
+import (
+	"net/http"
+)
+
+// The blueprints or batches of the server
+type collection struct {
+	plural   string
+	singular string
+	items    map[string]*datasource
+	order    []string
+}
+
+// A blueprint or batch, along with the feed created for it
+type datasource struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Visibility  string       `json:"visibility"`
+	Serial      *string      `json:"serial"`
+	Status      string       `json:"status"`
+	Feed        string       `json:"feed"`
+	URL         string       `json:"url"`
+	Key         string       `json:"key"`
+	Tags        []string     `json:"tags"`
+	Created     string       `json:"created"`
+	Updated     string       `json:"updated"`
+	Datasources *datasources `json:"datasources,omitempty"`
+}
+
+// The data sources counts of a batch
+type datasources struct {
+	Total        int `json:"total"`
+	Registered   int `json:"registered"`
+	Unregistered int `json:"unregistered"`
+}
+
+// The fields accepted when creating or updating a blueprint or batch
+type datasourceParams struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility"`
+}
+
+// Creates an empty collection
+func newCollection(plural string, singular string) *collection {
+	return &collection{plural: plural, singular: singular, items: make(map[string]*datasource)}
+}
+
+// Serves /blueprints and /batches
+func (s *Server) serveDatasources(w http.ResponseWriter, r *request, c *collection) {
+	if len(r.segments) > 2 {
+		writeMessage(w, 404, "Not Found")
+		return
+	}
+	if len(r.segments) == 1 {
+		if !r.allow(w, "GET", "POST") {
+			return
+		}
+		if r.Method == "GET" {
+			var items []interface{}
+			for _, id := range c.order {
+				items = append(items, c.items[id])
+			}
+			r.writePage(w, c.plural, items)
+			return
+		}
+		s.createDatasource(w, r, c)
+		return
+	}
+
+	item, ok := c.items[r.segment(1)]
+	if !ok {
+		writeNotFound(w, c.singular)
+		return
+	}
+	if !r.allow(w, "GET", "PUT", "DELETE") {
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, 200, item)
+	case "PUT":
+		params := &datasourceParams{}
+		if !r.decode(w, params) || !params.validate(false).check(w) {
+			return
+		}
+		params.apply(item)
+		item.Updated = formatTime(r.now)
+		if f, ok := s.feeds[item.ID]; ok {
+			f.Name, f.Description, f.Visibility, f.Updated = item.Name, item.Description, item.Visibility, item.Updated
+		}
+		w.WriteHeader(204)
+	case "DELETE":
+		delete(c.items, item.ID)
+		c.order = remove(c.order, item.ID)
+		s.deleteFeed(item.ID)
+		w.WriteHeader(204)
+	}
+}
+
+// Creates a blueprint or batch along with its feed and key
+func (s *Server) createDatasource(w http.ResponseWriter, r *request, c *collection) {
+	params := &datasourceParams{}
+	if !r.decode(w, params) || !params.validate(true).check(w) {
+		return
+	}
+	id := newID()
+	item := &datasource{
+		ID:      id,
+		Status:  "enabled",
+		Feed:    "/feeds/" + id,
+		URL:     "/" + c.plural + "/" + id,
+		Key:     newID(),
+		Tags:    []string{},
+		Created: formatTime(r.now),
+		Updated: formatTime(r.now),
+	}
+	if c.singular == "batch" {
+		item.Datasources = &datasources{}
+	}
+	params.apply(item)
+	c.items[id] = item
+	c.order = append(c.order, id)
+
+	s.addFeed(&feed{
+		ID:          id,
+		Name:        item.Name,
+		Description: item.Description,
+		Visibility:  item.Visibility,
+		Status:      item.Status,
+		Type:        c.singular,
+		Tags:        []string{},
+		URL:         item.Feed,
+		Key:         item.Key,
+		Created:     item.Created,
+		Updated:     item.Updated,
+	})
+	s.addKey(&key{
+		Name:        item.Name,
+		Key:         item.Key,
+		Feed:        item.Feed,
+		Permissions: []string{"DELETE", "GET", "POST", "PUT"},
+	})
+	writeJSON(w, 201, item)
+}
+
+// Checks the fields of a blueprint or batch, requiring the name and
+// visibility on creation
+func (p *datasourceParams) validate(create bool) validation {
+	errs := validation{}
+	if create || p.Name != nil {
+		errs.require("name", value(p.Name))
+	}
+	if create || p.Visibility != nil {
+		errs.require("visibility", value(p.Visibility))
+	}
+	errs.oneOf("visibility", value(p.Visibility), "public", "private")
+	return errs
+}
+
+// Sets the fields given in the params
+func (p *datasourceParams) apply(item *datasource) {
+	if p.Name != nil {
+		item.Name = *p.Name
+	}
+	if p.Description != nil {
+		item.Description = *p.Description
+	}
+	if p.Visibility != nil {
+		item.Visibility = *p.Visibility
+	}
+}
+
+// Returns the string pointed to by s, or "" if s is nil
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Returns ids without id
+func remove(ids []string, id string) []string {
+	for i := range ids {
+		if ids[i] == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
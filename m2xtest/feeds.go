@@ -0,0 +1,663 @@
+// Copyright (c) 2014 Jason Goecke
+// feeds.go
+
+package m2xtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of values returned when the limit parameter is not given
+const defaultValuesLimit = 100
+
+// Stream names accepted by the API
+var streamName = regexp.MustCompile(`^[\w\-]{1,255}$`)
+
+// A feed, with its location, streams, triggers and request log
+type feed struct {
+	ID          string
+	Name        string
+	Description string
+	Visibility  string
+	Status      string
+	Type        string
+	Tags        []string
+	URL         string
+	Key         string
+	Created     string
+	Updated     string
+
+	location     *location
+	streams      map[string]*stream
+	streamOrder  []string
+	triggers     map[string]*trigger
+	triggerOrder []string
+	log          []logEntry
+}
+
+// The location of a feed and its previous positions
+type location struct {
+	Name      string     `json:"name"`
+	Latitude  string     `json:"latitude"`
+	Longitude string     `json:"longitude"`
+	Elevation string     `json:"elevation"`
+	Waypoints []waypoint `json:"waypoints"`
+}
+
+// A previous position of a feed
+type waypoint struct {
+	Timestamp string `json:"timestamp"`
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+	Elevation string `json:"elevation"`
+}
+
+// A stream and its values, oldest first
+type stream struct {
+	Name    string
+	Unit    unit
+	URL     string
+	Created string
+	Updated string
+	values  []point
+}
+
+// The unit of a stream
+type unit struct {
+	Label  string `json:"label"`
+	Symbol string `json:"symbol"`
+}
+
+// A value of a stream, kept as the JSON number or string it was posted as
+type point struct {
+	at    time.Time
+	value json.RawMessage
+}
+
+// A request made on a feed
+type logEntry struct {
+	At     string `json:"at"`
+	Status int    `json:"status"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// A value as posted to the API
+type pointParams struct {
+	At    *string         `json:"at"`
+	Value json.RawMessage `json:"value"`
+}
+
+// A string posted either as a JSON string or as a JSON number
+type text string
+
+// UnmarshalJSON accepts a JSON string or number
+func (t *text) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = text(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*t = text(n)
+	return nil
+}
+
+// Adds a feed to the server
+func (s *Server) addFeed(f *feed) {
+	f.streams = make(map[string]*stream)
+	f.triggers = make(map[string]*trigger)
+	s.feeds[f.ID] = f
+	s.feedOrder = append(s.feedOrder, f.ID)
+}
+
+// Removes a feed along with the keys restricted to it
+func (s *Server) deleteFeed(id string) {
+	delete(s.feeds, id)
+	s.feedOrder = remove(s.feedOrder, id)
+	for _, k := range s.keyOrder {
+		if feedID(s.keys[k].Feed) == id {
+			s.deleteKey(k)
+		}
+	}
+}
+
+// Serves /feeds and the resources of each feed, logging the requests made on
+// a feed
+func (s *Server) serveFeeds(w http.ResponseWriter, r *request) {
+	if len(r.segments) == 1 {
+		if r.allow(w, "GET") {
+			s.listFeeds(w, r)
+		}
+		return
+	}
+	f, ok := s.feeds[r.segment(1)]
+	if !ok {
+		writeNotFound(w, "feed")
+		return
+	}
+	recorder := &statusRecorder{ResponseWriter: w, status: 200}
+	s.serveFeed(recorder, r, f)
+	f.log = append(f.log, logEntry{At: formatTime(r.now), Status: recorder.status, Method: r.Method, Path: r.URL.Path})
+}
+
+// Serves the resources of a feed
+func (s *Server) serveFeed(w http.ResponseWriter, r *request, f *feed) {
+	switch {
+	case len(r.segments) == 2:
+		if !r.allow(w, "GET", "POST") {
+			return
+		}
+		if r.Method == "GET" {
+			writeJSON(w, 200, f.view())
+			return
+		}
+		postFeedValues(w, r, f)
+	case r.segment(2) == "location" && len(r.segments) == 3:
+		if r.allow(w, "GET", "PUT") {
+			serveLocation(w, r, f)
+		}
+	case r.segment(2) == "streams" && len(r.segments) <= 5:
+		serveStreams(w, r, f)
+	case r.segment(2) == "triggers" && len(r.segments) <= 5:
+		serveTriggers(w, r, f)
+	case r.segment(2) == "log" && len(r.segments) == 3:
+		if r.allow(w, "GET") {
+			requests := make([]logEntry, 0, len(f.log))
+			for i := len(f.log) - 1; i >= 0; i-- {
+				requests = append(requests, f.log[i])
+			}
+			writeJSON(w, 200, map[string]interface{}{"requests": requests})
+		}
+	default:
+		writeMessage(w, 404, "Not Found")
+	}
+}
+
+// Lists the feeds matching the search filters
+func (s *Server) listFeeds(w http.ResponseWriter, r *request) {
+	query := r.URL.Query()
+	errs := validation{}
+	errs.oneOf("type", query.Get("type"), "blueprint", "batch", "datasource")
+	errs.oneOf("visibility", query.Get("visibility"), "public", "private")
+	errs.oneOf("distance_unit", query.Get("distance_unit"), "mi", "miles", "km")
+	near := query.Get("latitude") != "" || query.Get("longitude") != "" || query.Get("distance") != ""
+	var latitude, longitude, radius float64
+	if near {
+		latitude = parseCoordinate(errs, "latitude", query.Get("latitude"), 90)
+		longitude = parseCoordinate(errs, "longitude", query.Get("longitude"), 180)
+		var err error
+		if radius, err = strconv.ParseFloat(query.Get("distance"), 64); err != nil || radius < 0 {
+			errs.add("distance", "is not a number")
+		}
+		if query.Get("distance_unit") == "km" {
+			radius /= 1.609344
+		}
+	}
+	if !errs.check(w) {
+		return
+	}
+
+	search := strings.ToLower(query.Get("q"))
+	var tags []string
+	if query.Get("tags") != "" {
+		tags = strings.Split(query.Get("tags"), ",")
+	}
+	items := []interface{}{}
+	for _, id := range s.feedOrder {
+		f := s.feeds[id]
+		switch {
+		case search != "" && !strings.Contains(strings.ToLower(f.Name+" "+f.Description), search):
+		case query.Get("type") != "" && f.Type != query.Get("type"):
+		case query.Get("visibility") != "" && f.Visibility != query.Get("visibility"):
+		case !hasTags(f.Tags, tags):
+		case near && !f.near(latitude, longitude, radius):
+		default:
+			items = append(items, f.view())
+		}
+	}
+	r.writePage(w, "feeds", items)
+}
+
+// Returns the JSON representation of a feed
+func (f *feed) view() map[string]interface{} {
+	streams := []interface{}{}
+	for _, name := range f.streamOrder {
+		streams = append(streams, f.streams[name].view())
+	}
+	triggers := []interface{}{}
+	for _, id := range f.triggerOrder {
+		triggers = append(triggers, f.triggers[id])
+	}
+	var loc interface{} = map[string]interface{}{}
+	if f.location != nil {
+		loc = f.location
+	}
+	return map[string]interface{}{
+		"id":          f.ID,
+		"name":        f.Name,
+		"description": f.Description,
+		"visibility":  f.Visibility,
+		"status":      f.Status,
+		"type":        f.Type,
+		"tags":        f.Tags,
+		"url":         f.URL,
+		"key":         f.Key,
+		"created":     f.Created,
+		"updated":     f.Updated,
+		"location":    loc,
+		"streams":     streams,
+		"triggers":    triggers,
+	}
+}
+
+// Reports whether the feed is located within radius miles of a point
+func (f *feed) near(latitude float64, longitude float64, radius float64) bool {
+	if f.location == nil {
+		return false
+	}
+	lat, err1 := strconv.ParseFloat(f.location.Latitude, 64)
+	long, err2 := strconv.ParseFloat(f.location.Longitude, 64)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	const earthRadius = 3958.8
+	rad := math.Pi / 180
+	dLat, dLong := (lat-latitude)*rad, (long-longitude)*rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(latitude*rad)*math.Cos(lat*rad)*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2*earthRadius*math.Asin(math.Sqrt(a)) <= radius
+}
+
+// Reports whether tags includes every one of wanted
+func hasTags(tags []string, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, tag := range tags {
+			found = found || tag == w
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Serves the location of a feed
+func serveLocation(w http.ResponseWriter, r *request, f *feed) {
+	if r.Method == "GET" {
+		if f.location == nil {
+			writeJSON(w, 200, map[string]interface{}{})
+			return
+		}
+		writeJSON(w, 200, f.location)
+		return
+	}
+	params := struct {
+		Name      text `json:"name"`
+		Latitude  text `json:"latitude"`
+		Longitude text `json:"longitude"`
+		Elevation text `json:"elevation"`
+	}{}
+	if !r.decode(w, &params) {
+		return
+	}
+	errs := validation{}
+	parseCoordinate(errs, "latitude", string(params.Latitude), 90)
+	parseCoordinate(errs, "longitude", string(params.Longitude), 180)
+	if params.Elevation != "" {
+		if _, err := strconv.ParseFloat(string(params.Elevation), 64); err != nil {
+			errs.add("elevation", "is not a number")
+		}
+	}
+	if !errs.check(w) {
+		return
+	}
+	var waypoints []waypoint
+	if f.location != nil {
+		waypoints = f.location.Waypoints
+	}
+	f.location = &location{
+		Name:      string(params.Name),
+		Latitude:  string(params.Latitude),
+		Longitude: string(params.Longitude),
+		Elevation: string(params.Elevation),
+		Waypoints: append([]waypoint{{
+			Timestamp: formatTime(r.now),
+			Latitude:  string(params.Latitude),
+			Longitude: string(params.Longitude),
+			Elevation: string(params.Elevation),
+		}}, waypoints...),
+	}
+	w.WriteHeader(202)
+}
+
+// Parses a coordinate in decimal degrees, adding a validation error if it is
+// missing or out of range
+func parseCoordinate(errs validation, field string, value string, limit float64) float64 {
+	if value == "" {
+		errs.add(field, "can't be blank")
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.Abs(f) > limit {
+		errs.add(field, "is not a valid coordinate")
+	}
+	return f
+}
+
+// Serves the streams of a feed and their values
+func serveStreams(w http.ResponseWriter, r *request, f *feed) {
+	if len(r.segments) == 3 {
+		if r.allow(w, "GET") {
+			streams := []interface{}{}
+			for _, name := range f.streamOrder {
+				streams = append(streams, f.streams[name].view())
+			}
+			writeJSON(w, 200, map[string]interface{}{"streams": streams})
+		}
+		return
+	}
+	name := r.segment(3)
+	st, ok := f.streams[name]
+	if len(r.segments) == 5 {
+		if r.segment(4) != "values" {
+			writeMessage(w, 404, "Not Found")
+			return
+		}
+		if !r.allow(w, "GET", "POST", "DELETE") {
+			return
+		}
+		switch {
+		case r.Method == "POST":
+			postStreamValues(w, r, f, name)
+		case !ok:
+			writeNotFound(w, "stream")
+		case r.Method == "GET":
+			listValues(w, r, st)
+		default:
+			deleteValues(w, r, st)
+		}
+		return
+	}
+
+	if !r.allow(w, "GET", "PUT", "DELETE") {
+		return
+	}
+	if r.Method == "PUT" {
+		params := struct {
+			Unit *unit `json:"unit"`
+		}{}
+		if !r.decode(w, &params) {
+			return
+		}
+		errs := validation{}
+		if !streamName.MatchString(name) {
+			errs.add("name", "is invalid")
+		}
+		if !errs.check(w) {
+			return
+		}
+		st = f.stream(name, r.now)
+		if params.Unit != nil {
+			st.Unit = *params.Unit
+		}
+		st.Updated = formatTime(r.now)
+		writeJSON(w, 201, st.view())
+		return
+	}
+	if !ok {
+		writeNotFound(w, "stream")
+		return
+	}
+	if r.Method == "GET" {
+		writeJSON(w, 200, st.view())
+		return
+	}
+	delete(f.streams, name)
+	f.streamOrder = remove(f.streamOrder, name)
+	w.WriteHeader(204)
+}
+
+// Returns the named stream of the feed, creating it if needed
+func (f *feed) stream(name string, now time.Time) *stream {
+	st, ok := f.streams[name]
+	if !ok {
+		st = &stream{
+			Name:    name,
+			URL:     f.URL + "/streams/" + name,
+			Created: formatTime(now),
+			Updated: formatTime(now),
+		}
+		f.streams[name] = st
+		f.streamOrder = append(f.streamOrder, name)
+	}
+	return st
+}
+
+// Returns the JSON representation of a stream, with its latest, lowest and
+// highest values
+func (st *stream) view() map[string]interface{} {
+	var latest, min, max interface{}
+	if len(st.values) > 0 {
+		latest = st.values[len(st.values)-1].value
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, p := range st.values {
+			if n, err := strconv.ParseFloat(strings.Trim(string(p.value), `"`), 64); err == nil {
+				low, high = math.Min(low, n), math.Max(high, n)
+			}
+		}
+		if !math.IsInf(low, 0) {
+			min, max = low, high
+		}
+	}
+	return map[string]interface{}{
+		"name":    st.Name,
+		"value":   latest,
+		"min":     min,
+		"max":     max,
+		"unit":    st.Unit,
+		"url":     st.URL,
+		"created": st.Created,
+		"updated": st.Updated,
+	}
+}
+
+// Adds values to a stream, keeping them sorted by time
+func (st *stream) add(points []point, now time.Time) {
+	st.values = append(st.values, points...)
+	sort.SliceStable(st.values, func(i, j int) bool { return st.values[i].at.Before(st.values[j].at) })
+	st.Updated = formatTime(now)
+}
+
+// Checks posted values, returning them as points
+func parsePoints(errs validation, field string, values []pointParams, now time.Time) []point {
+	if len(values) == 0 {
+		errs.add(field, "can't be blank")
+		return nil
+	}
+	points := make([]point, 0, len(values))
+	for _, v := range values {
+		p := point{at: now, value: v.Value}
+		if v.At != nil {
+			at, err := time.Parse(time.RFC3339Nano, *v.At)
+			if err != nil {
+				errs.add(field, "has a value with an invalid timestamp")
+				continue
+			}
+			p.at = at
+		}
+		trimmed := bytes.TrimSpace(v.Value)
+		if len(trimmed) == 0 || (trimmed[0] != '"' && trimmed[0] != '-' && (trimmed[0] < '0' || trimmed[0] > '9')) {
+			errs.add(field, "has a value that is not a number or a string")
+			continue
+		}
+		p.value = trimmed
+		points = append(points, p)
+	}
+	return points
+}
+
+// Posts values to a stream, creating the stream if needed
+func postStreamValues(w http.ResponseWriter, r *request, f *feed, name string) {
+	params := struct {
+		Values []pointParams `json:"values"`
+	}{}
+	if !r.decode(w, &params) {
+		return
+	}
+	errs := validation{}
+	if !streamName.MatchString(name) {
+		errs.add("name", "is invalid")
+	}
+	points := parsePoints(errs, "values", params.Values, r.now)
+	if !errs.check(w) {
+		return
+	}
+	f.stream(name, r.now).add(points, r.now)
+	w.WriteHeader(202)
+}
+
+// Posts values to several streams of a feed
+func postFeedValues(w http.ResponseWriter, r *request, f *feed) {
+	params := struct {
+		Values map[string][]pointParams `json:"values"`
+	}{}
+	if !r.decode(w, &params) {
+		return
+	}
+	errs := validation{}
+	if len(params.Values) == 0 {
+		errs.add("values", "can't be blank")
+	}
+	streams := map[string][]point{}
+	for name, values := range params.Values {
+		if !streamName.MatchString(name) {
+			errs.add("values", "has an invalid stream name")
+		}
+		streams[name] = parsePoints(errs, "values", values, r.now)
+	}
+	if !errs.check(w) {
+		return
+	}
+	names := make([]string, 0, len(streams))
+	for name := range streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.stream(name, r.now).add(streams[name], r.now)
+	}
+	w.WriteHeader(202)
+}
+
+// Lists the values of a stream within the requested window, newest first
+// unless asked otherwise
+func listValues(w http.ResponseWriter, r *request, st *stream) {
+	query := r.URL.Query()
+	errs := validation{}
+	start := parseTime(errs, "start", query.Get("start"))
+	end := parseTime(errs, "end", query.Get("end"))
+	limit := defaultValuesLimit
+	if query.Get("limit") != "" {
+		var err error
+		if limit, err = strconv.Atoi(query.Get("limit")); err != nil || limit < 1 {
+			errs.add("limit", "is not a positive number")
+		}
+	}
+	errs.oneOf("order", query.Get("order"), "asc", "desc")
+	if !errs.check(w) {
+		return
+	}
+
+	values := []interface{}{}
+	n := len(st.values)
+	for i := 0; i < n && len(values) < limit; i++ {
+		p := st.values[n-1-i]
+		if query.Get("order") == "asc" {
+			p = st.values[i]
+		}
+		if (start.IsZero() || !p.at.Before(start)) && (end.IsZero() || !p.at.After(end)) {
+			values = append(values, map[string]interface{}{"at": formatTime(p.at), "value": p.value})
+		}
+	}
+	writeJSON(w, 200, map[string]interface{}{
+		"start":  nullable(query.Get("start")),
+		"end":    nullable(query.Get("end")),
+		"limit":  limit,
+		"values": values,
+	})
+}
+
+// Deletes the values of a stream within a range
+func deleteValues(w http.ResponseWriter, r *request, st *stream) {
+	params := struct {
+		From string `json:"from"`
+		End  string `json:"end"`
+	}{}
+	if !r.decode(w, &params) {
+		return
+	}
+	errs := validation{}
+	errs.require("from", params.From)
+	errs.require("end", params.End)
+	from := parseTime(errs, "from", params.From)
+	end := parseTime(errs, "end", params.End)
+	if !from.IsZero() && !end.IsZero() && end.Before(from) {
+		errs.add("end", "must not be before from")
+	}
+	if !errs.check(w) {
+		return
+	}
+	kept := st.values[:0]
+	for _, p := range st.values {
+		if p.at.Before(from) || p.at.After(end) {
+			kept = append(kept, p)
+		}
+	}
+	st.values = kept
+	st.Updated = formatTime(r.now)
+	w.WriteHeader(204)
+}
+
+// Parses an optional timestamp, adding a validation error if it is invalid
+func parseTime(errs validation, field string, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		errs.add(field, "is not a valid timestamp")
+	}
+	return t
+}
+
+// Returns nil for an empty string, so it is encoded as null
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.status = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2014 Jason Goecke
+// feeds_test.go
+
+package m2xtest_test
+
+import (
+	"testing"
+	"time"
+
+	m2x "github.com/jsgoecke/m2x-go"
+)
+
+func TestStreamValues(t *testing.T) {
+	_, client := newClient(t)
+	blueprint, _ := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Sensor", Visibility: "private"})
+
+	if _, errorMessage := client.FeedStream(blueprint.Feed, "temperature"); !m2x.IsNotFound(errorMessage) || errorMessage.Message != "The specified stream does not exist" {
+		t.Errorf("A missing stream should not be found: %v", errorMessage)
+	}
+	errorMessage := client.UpdateFeedStreamWithParams(blueprint.Feed, "temperature", &m2x.StreamParams{Unit: &m2x.Unit{Label: "celsius", Symbol: "C"}})
+	if errorMessage != nil {
+		t.Errorf("Did not create the stream properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.FeedHandle(blueprint.ID).Stream("temperature/values").Get(); !m2x.IsNotFound(errorMessage) || errorMessage.Message != "The specified stream does not exist" {
+		t.Errorf("An escaped slash should be part of the stream name: %v", errorMessage)
+	}
+
+	first := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	values := m2x.NewFeedValues()
+	for i := 0; i < 5; i++ {
+		values.Add("temperature", first.Add(time.Duration(i)*time.Minute), m2x.Int64Value(int64(20+i)))
+	}
+	values.Add("humidity", first, m2x.StringValue("40"))
+	if errorMessage := client.UpdateFeedValues(blueprint.Feed, values); errorMessage != nil {
+		t.Errorf("Did not post the values properly: %v", errorMessage)
+	}
+
+	stream, _ := client.FeedStream(blueprint.Feed, "temperature")
+	if stream.Unit.Symbol != "C" || stream.Value.String() != "24" || stream.Min.String() != "20" || stream.Max.String() != "24" {
+		t.Errorf("The stream did not report its values properly: %+v", stream)
+	}
+	streams, _ := client.FeedStreams(blueprint.Feed)
+	if len(streams.Streams) != 2 || streams.Streams[1].Name != "humidity" {
+		t.Errorf("The stream posted through the feed should have been created")
+	}
+
+	window, errorMessage := client.QueryFeedStreamValues(blueprint.Feed, "temperature", &m2x.ValuesQuery{Start: first.Add(time.Minute), Limit: 2})
+	if errorMessage != nil || len(window.Values) != 2 || window.Values[0].Value.String() != "24" || window.Limit != 2 {
+		t.Errorf("Values were not listed newest first within the window: %+v", window)
+	}
+	window, _ = client.QueryFeedStreamValues(blueprint.Feed, "temperature", &m2x.ValuesQuery{Order: "asc", Limit: 1})
+	if window.Values[0].Value.String() != "20" {
+		t.Errorf("Values were not listed oldest first")
+	}
+
+	errorMessage = client.DeleteFeedStreamValues(blueprint.Feed, "temperature", &m2x.ValuesRange{From: first.Add(time.Minute), End: first.Add(3 * time.Minute)})
+	if errorMessage != nil {
+		t.Errorf("Did not delete the values properly: %v", errorMessage)
+	}
+	if all, _ := client.FeedStreamValues(blueprint.Feed, "temperature"); len(all.Values) != 2 || all.Limit != 100 {
+		t.Errorf("Values within the range should have been deleted: %+v", all)
+	}
+
+	errorMessage = client.UpdateFeedStreamValues(blueprint.Feed, "temperature", map[string]interface{}{"values": []map[string]interface{}{{"at": "yesterday", "value": 1}}})
+	if !m2x.IsValidation(errorMessage) || errorMessage.StatusCode != 422 {
+		t.Errorf("A value with an invalid timestamp should have been rejected: %v", errorMessage)
+	}
+
+	if errorMessage := client.DeleteFeedStream(blueprint.Feed, "humidity"); errorMessage != nil {
+		t.Errorf("Did not delete the stream properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.FeedStream(blueprint.Feed, "humidity"); !m2x.IsNotFound(errorMessage) {
+		t.Errorf("The stream should have been deleted")
+	}
+}
+
+func TestLocationAndSearch(t *testing.T) {
+	_, client := newClient(t)
+	seville, _ := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Seville", Visibility: "private"})
+	client.CreateBatchWithParams(&m2x.BatchParams{Name: "Madrid", Visibility: "public"})
+
+	errorMessage := client.UpdateFeedLocationWithParams(seville.Feed, &m2x.LocationParams{Name: "Warehouse", Latitude: "37.383055", Longitude: "-5.996392"})
+	if errorMessage != nil {
+		t.Errorf("Did not update the location properly: %v", errorMessage)
+	}
+	location, _ := client.FeedLocation(seville.Feed)
+	if location.Name != "Warehouse" || len(location.Waypoints) != 1 || location.Waypoints[0].Timestamp.IsZero() {
+		t.Errorf("Did not get the location properly: %+v", location)
+	}
+	errorMessage = client.UpdateFeedLocation(seville.Feed, map[string]interface{}{"latitude": 137})
+	if !m2x.IsValidation(errorMessage) || len(errorMessage.FieldErrors()) != 2 {
+		t.Errorf("An invalid location should have been rejected: %v", errorMessage)
+	}
+
+	near, _ := client.SearchFeeds(&m2x.FeedQuery{Latitude: "37.39", Longitude: "-5.98", Distance: "5", DistanceUnit: "km"})
+	if near.Total != 1 || near.Feeds[0].ID != seville.ID {
+		t.Errorf("Only the feed near the location should have been found: %+v", near)
+	}
+	batches, _ := client.SearchFeeds(&m2x.FeedQuery{Type: "batch", Query: "madrid"})
+	if batches.Total != 1 || batches.Feeds[0].Name != "Madrid" {
+		t.Errorf("Only the batch feed should have been found: %+v", batches)
+	}
+}
+
+func TestTriggersAndLog(t *testing.T) {
+	_, client := newClient(t)
+	blueprint, _ := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Sensor", Visibility: "private"})
+
+	params := &m2x.TriggerParams{Name: "Too hot", Stream: "temperature", Condition: ">", Value: "30", CallbackURL: "http://example.com/hook"}
+	if _, errorMessage := client.CreateTriggerWithParams(blueprint.Feed, params); !m2x.IsValidation(errorMessage) || errorMessage.Errors["stream"] == nil {
+		t.Errorf("A trigger on a missing stream should have been rejected: %v", errorMessage)
+	}
+	client.UpdateFeedStreamWithParams(blueprint.Feed, "temperature", &m2x.StreamParams{})
+	trigger, errorMessage := client.CreateTriggerWithParams(blueprint.Feed, params)
+	if errorMessage != nil || trigger.Status != "enabled" || trigger.Value != "30" {
+		t.Fatalf("Did not create the trigger properly: %v", errorMessage)
+	}
+
+	if errorMessage := client.UpdateTriggerWithParams(blueprint.Feed, trigger.ID, &m2x.TriggerParams{Status: "disabled"}); errorMessage != nil {
+		t.Errorf("Did not update the trigger properly: %v", errorMessage)
+	}
+	if trigger, _ = client.Trigger(blueprint.Feed, trigger.ID); trigger.Status != "disabled" || trigger.Name != "Too hot" {
+		t.Errorf("Only the status of the trigger should have been updated")
+	}
+	if errorMessage := client.TestTrigger(blueprint.Feed, trigger.ID); errorMessage != nil {
+		t.Errorf("Did not test the trigger properly: %v", errorMessage)
+	}
+	if triggers, _ := client.Triggers(blueprint.Feed); len(triggers.Triggers) != 1 {
+		t.Errorf("Did not list the triggers properly")
+	}
+	if errorMessage := client.DeleteTrigger(blueprint.Feed, trigger.ID); errorMessage != nil {
+		t.Errorf("Did not delete the trigger properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.Trigger(blueprint.Feed, trigger.ID); errorMessage == nil || errorMessage.Message != "The specified trigger does not exist" {
+		t.Errorf("The trigger should have been deleted: %v", errorMessage)
+	}
+
+	log, _ := client.RequestLog(blueprint.Feed)
+	if len(log.Requests) != 9 || log.Requests[0].Method != "GET" || log.Requests[0].Status != 404 || log.Requests[8].Status != 422 {
+		t.Errorf("The requests on the feed were not logged properly: %+v", log.Requests)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2014 Jason Goecke
+// keys.go
+
+package m2xtest
+
+import (
+	"net/http"
+	"time"
+)
+
+// An API key, which may be restricted to a feed and one of its streams
+type key struct {
+	Name        string   `json:"name"`
+	Key         string   `json:"key"`
+	Master      bool     `json:"master"`
+	Feed        string   `json:"feed"`
+	Stream      string   `json:"stream"`
+	ExpiresAt   *string  `json:"expires_at"`
+	Permissions []string `json:"permissions"`
+}
+
+// The fields accepted when creating or updating a key
+type keyParams struct {
+	Name        *string   `json:"name"`
+	Permissions *[]string `json:"permissions"`
+	Feed        *string   `json:"feed"`
+	Stream      *string   `json:"stream"`
+	ExpiresAt   *string   `json:"expires_at"`
+}
+
+// Adds a key to the server
+func (s *Server) addKey(k *key) {
+	k.Master = k.Feed == ""
+	s.keys[k.Key] = k
+	s.keyOrder = append(s.keyOrder, k.Key)
+}
+
+// Removes a key from the server
+func (s *Server) deleteKey(k string) {
+	delete(s.keys, k)
+	s.keyOrder = remove(s.keyOrder, k)
+}
+
+// Reports whether the key has expired
+func (k *key) expired(now time.Time) bool {
+	if k.ExpiresAt == nil {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, *k.ExpiresAt)
+	return err == nil && !now.Before(expiresAt)
+}
+
+// Reports whether the key allows requests with the given method
+func (k *key) allows(method string) bool {
+	for _, permission := range k.Permissions {
+		if permission == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Serves /keys
+func (s *Server) serveKeys(w http.ResponseWriter, r *request) {
+	if len(r.segments) > 2 {
+		writeMessage(w, 404, "Not Found")
+		return
+	}
+	if len(r.segments) == 1 {
+		if !r.allow(w, "GET", "POST") {
+			return
+		}
+		if r.Method == "GET" {
+			s.listKeys(w, r)
+			return
+		}
+		params := &keyParams{}
+		if !r.decode(w, params) || !s.validateKey(params, true).check(w) {
+			return
+		}
+		k := &key{Key: newID()}
+		params.apply(k)
+		s.addKey(k)
+		writeJSON(w, 201, k)
+		return
+	}
+
+	k, ok := s.keys[r.segment(1)]
+	if !ok {
+		writeNotFound(w, "key")
+		return
+	}
+	if !r.allow(w, "GET", "PUT", "DELETE") {
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, 200, k)
+	case "PUT":
+		params := &keyParams{}
+		if !r.decode(w, params) || !s.validateKey(params, false).check(w) {
+			return
+		}
+		params.apply(k)
+		k.Master = k.Feed == ""
+		w.WriteHeader(204)
+	case "DELETE":
+		s.deleteKey(k.Key)
+		w.WriteHeader(204)
+	}
+}
+
+// Lists the keys, restricted to those of a feed or stream if asked
+func (s *Server) listKeys(w http.ResponseWriter, r *request) {
+	query := r.URL.Query()
+	errs := validation{}
+	if query.Get("stream") != "" {
+		errs.require("feed", query.Get("feed"))
+	}
+	if !errs.check(w) {
+		return
+	}
+	items := []interface{}{}
+	for _, id := range s.keyOrder {
+		k := s.keys[id]
+		if query.Get("feed") != "" && feedID(k.Feed) != feedID(query.Get("feed")) {
+			continue
+		}
+		if query.Get("stream") != "" && k.Stream != query.Get("stream") {
+			continue
+		}
+		items = append(items, k)
+	}
+	r.writePage(w, "keys", items)
+}
+
+// Checks the fields of a key, requiring the name and permissions on creation
+func (s *Server) validateKey(p *keyParams, create bool) validation {
+	errs := validation{}
+	if create || p.Name != nil {
+		errs.require("name", value(p.Name))
+	}
+	if create || p.Permissions != nil {
+		if p.Permissions == nil || len(*p.Permissions) == 0 {
+			errs.add("permissions", "can't be blank")
+		} else {
+			for _, permission := range *p.Permissions {
+				errs.oneOf("permissions", permission, "GET", "POST", "PUT", "DELETE")
+			}
+		}
+	}
+	if feed := value(p.Feed); feed != "" {
+		if _, ok := s.feeds[feedID(feed)]; !ok {
+			errs.add("feed", "does not exist")
+		}
+	}
+	if value(p.Stream) != "" && value(p.Feed) == "" {
+		errs.add("stream", "requires a feed")
+	}
+	if p.ExpiresAt != nil && *p.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339Nano, *p.ExpiresAt); err != nil {
+			errs.add("expires_at", "is not a valid timestamp")
+		}
+	}
+	return errs
+}
+
+// Sets the fields given in the params
+func (p *keyParams) apply(k *key) {
+	if p.Name != nil {
+		k.Name = *p.Name
+	}
+	if p.Permissions != nil {
+		k.Permissions = *p.Permissions
+	}
+	if p.Feed != nil {
+		k.Feed = ""
+		if *p.Feed != "" {
+			k.Feed = "/feeds/" + feedID(*p.Feed)
+		}
+	}
+	if p.Stream != nil {
+		k.Stream = *p.Stream
+	}
+	if p.ExpiresAt != nil {
+		k.ExpiresAt = p.ExpiresAt
+		if *p.ExpiresAt == "" {
+			k.ExpiresAt = nil
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2014 Jason Goecke
+// server.go
+
+// Package m2xtest provides an in-memory fake of the M2X API for tests, so code
+// using the m2x package can be exercised without network access or an account.
+//
+//		server := m2xtest.NewServer()
+//		defer server.Close()
+//		client := m2x.NewClient(server.APIKey)
+//		client.APIBase = server.URL
+package m2xtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of items in a page when the limit parameter is not given
+const defaultPageLimit = 10
+
+// Server is a fake M2X API backed by memory. It implements feeds, streams,
+// values, locations, triggers, blueprints, batches, keys, the request log and
+// the status resource, answering with the status codes and error bodies of
+// the real API. A Server may be used from several goroutines.
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the master key accepted by the server. Keys created through
+	// the /keys resource are accepted too, within their permissions.
+	APIKey string
+
+	// Now returns the time used for created and updated dates, the request
+	// log and values posted without a timestamp. It defaults to time.Now and
+	// should be set before the first request.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	blueprints *collection
+	batches    *collection
+	feeds      map[string]*feed
+	feedOrder  []string
+	keys       map[string]*key
+	keyOrder   []string
+	failures   []int
+}
+
+// NewServer starts a fake M2X API with a random master key. The server must be
+// closed when no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:     newID(),
+		Now:        time.Now,
+		blueprints: newCollection("blueprints", "blueprint"),
+		batches:    newCollection("batches", "batch"),
+		feeds:      make(map[string]*feed),
+		keys:       make(map[string]*key),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Fail makes the next n requests fail with statusCode, to exercise error
+// handling and retries
+func (s *Server) Fail(n int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, statusCode)
+	}
+}
+
+// ServeHTTP routes a request to the fake resources
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		statusCode := s.failures[0]
+		s.failures = s.failures[1:]
+		writeMessage(w, statusCode, http.StatusText(statusCode))
+		return
+	}
+	// Segments are split before being unescaped, so names may contain slashes
+	path := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/v1"), "/")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	if segments[0] == "status" && len(segments) == 1 {
+		if r.Method != "GET" {
+			writeMessage(w, 405, "Method Not Allowed")
+			return
+		}
+		writeJSON(w, 200, map[string]string{"api": "OK", "triggers": "OK"})
+		return
+	}
+	if statusCode := s.authorize(r, segments); statusCode != 0 {
+		writeMessage(w, statusCode, http.StatusText(statusCode))
+		return
+	}
+
+	req := &request{Request: r, segments: segments, now: s.Now().UTC()}
+	switch segments[0] {
+	case "blueprints":
+		s.serveDatasources(w, req, s.blueprints)
+	case "batches":
+		s.serveDatasources(w, req, s.batches)
+	case "feeds":
+		s.serveFeeds(w, req)
+	case "keys":
+		s.serveKeys(w, req)
+	default:
+		writeMessage(w, 404, "Not Found")
+	}
+}
+
+// Checks the key of a request, returning the status code to answer with when
+// it is not allowed
+func (s *Server) authorize(r *http.Request, segments []string) int {
+	apiKey := r.Header.Get("X-M2X-KEY")
+	if apiKey == "" {
+		return 401
+	}
+	if apiKey == s.APIKey {
+		return 0
+	}
+	k, ok := s.keys[apiKey]
+	if !ok || k.expired(s.Now()) {
+		return 401
+	}
+	if !k.allows(r.Method) {
+		return 403
+	}
+	if k.Feed != "" && (segments[0] != "feeds" || len(segments) < 2 || segments[1] != feedID(k.Feed)) {
+		return 403
+	}
+	if k.Stream != "" && (len(segments) < 4 || segments[2] != "streams" || segments[3] != k.Stream) {
+		return 403
+	}
+	return 0
+}
+
+// A request being served, split into path segments
+type request struct {
+	*http.Request
+	segments []string
+	now      time.Time
+}
+
+// Returns the path segment at index i, or "" if the path is shorter
+func (r *request) segment(i int) string {
+	if i < len(r.segments) {
+		return r.segments[i]
+	}
+	return ""
+}
+
+// Decodes the JSON body of the request into v, answering with 400 if it is
+// not valid JSON
+func (r *request) decode(w http.ResponseWriter, v interface{}) bool {
+	data, err := ioutil.ReadAll(r.Body)
+	if err == nil && len(data) > 0 {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		writeMessage(w, 400, "Problems parsing JSON")
+		return false
+	}
+	return true
+}
+
+// Returns the requested page and limit
+func (r *request) page() (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	return page, limit
+}
+
+// Writes a page of items under name along with the paging fields
+func (r *request) writePage(w http.ResponseWriter, name string, items []interface{}) {
+	page, limit := r.page()
+	pages := (len(items) + limit - 1) / limit
+	if pages == 0 {
+		pages = 1
+	}
+	start := (page - 1) * limit
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	writeJSON(w, 200, map[string]interface{}{
+		name:           items[start:end],
+		"total":        len(items),
+		"pages":        pages,
+		"limit":        limit,
+		"current_page": page,
+	})
+}
+
+// Answers with 405 unless the request uses one of the given methods
+func (r *request) allow(w http.ResponseWriter, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	writeMessage(w, 405, "Method Not Allowed")
+	return false
+}
+
+// Validation errors by field, as returned by the API along with a 422
+type validation map[string][]string
+
+// Adds a message for a field
+func (v validation) add(field string, message string) {
+	v[field] = append(v[field], message)
+}
+
+// Adds a message if a required field is blank
+func (v validation) require(field string, value string) {
+	if value == "" {
+		v.add(field, "can't be blank")
+	}
+}
+
+// Adds a message if a field is set to a value outside of allowed
+func (v validation) oneOf(field string, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, "is not included in the list")
+}
+
+// Answers with 422 and the validation errors, returning false, if there are any
+func (v validation) check(w http.ResponseWriter) bool {
+	if len(v) == 0 {
+		return true
+	}
+	writeJSON(w, 422, map[string]interface{}{"message": "Validation Failed", "errors": v})
+	return false
+}
+
+// Writes v as the JSON body of the response
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Writes an error body with the given message
+func writeMessage(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{"message": message})
+}
+
+// Writes the 404 returned for a missing resource
+func writeNotFound(w http.ResponseWriter, resource string) {
+	writeMessage(w, 404, "The specified "+resource+" does not exist")
+}
+
+// Generates a random identifier in the format used by the API for ids and keys
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// Formats a time the way the API does
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Strips the /feeds/ prefix from a feed given by its resource
+func feedID(feed string) string {
+	return strings.TrimPrefix(feed, "/feeds/")
+}
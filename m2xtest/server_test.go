@@ -0,0 +1,136 @@
+// Copyright (c) 2014 Jason Goecke
+// server_test.go
+
+package m2xtest_test
+
+import (
+	"testing"
+
+	m2x "github.com/jsgoecke/m2x-go"
+	"github.com/jsgoecke/m2x-go/m2xtest"
+)
+
+// Starts a fake server and returns a client using its master key
+func newClient(t *testing.T) (*m2xtest.Server, *m2x.Client) {
+	server := m2xtest.NewServer()
+	t.Cleanup(server.Close)
+	client := m2x.NewClient(server.APIKey)
+	client.APIBase = server.URL
+	return server, client
+}
+
+func TestStatus(t *testing.T) {
+	_, client := newClient(t)
+	status, err := client.Status()
+	if err != nil || status.API != "OK" || status.Triggers != "OK" {
+		t.Errorf("Did not get the status properly: %v", err)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	server, client := newClient(t)
+	blueprint, _ := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Sensor", Visibility: "private"})
+	client.UpdateFeedStreamWithParams(blueprint.Feed, "temperature", &m2x.StreamParams{})
+
+	stranger := m2x.NewClient("1234")
+	stranger.APIBase = server.URL
+	if _, errorMessage := stranger.Blueprints(); !m2x.IsUnauthorized(errorMessage) || errorMessage.StatusCode != 401 {
+		t.Errorf("An unknown key should not be accepted: %v", errorMessage)
+	}
+
+	key, errorMessage := client.CreateKeyWithParams(&m2x.KeyParams{Name: "Reader", Permissions: []string{"GET"}, Feed: blueprint.Feed, Stream: "temperature"})
+	if errorMessage != nil || key.Master || key.Feed != blueprint.Feed {
+		t.Fatalf("Did not create a stream key properly: %v", errorMessage)
+	}
+	reader := m2x.NewClient(key.Key)
+	reader.APIBase = server.URL
+	if _, errorMessage := reader.FeedStream(blueprint.Feed, "temperature"); errorMessage != nil {
+		t.Errorf("The key should allow reading its stream: %v", errorMessage)
+	}
+	if _, errorMessage := reader.Feed(blueprint.Feed); errorMessage == nil || errorMessage.StatusCode != 403 {
+		t.Errorf("The key should only allow its stream: %v", errorMessage)
+	}
+	if errorMessage := reader.DeleteFeedStream(blueprint.Feed, "temperature"); errorMessage == nil || errorMessage.StatusCode != 403 {
+		t.Errorf("The key should only allow reading: %v", errorMessage)
+	}
+}
+
+func TestFail(t *testing.T) {
+	server, client := newClient(t)
+	server.Fail(1, 503)
+	if _, errorMessage := client.Blueprints(); errorMessage == nil || errorMessage.StatusCode != 503 {
+		t.Errorf("The request should have failed: %v", errorMessage)
+	}
+	if _, errorMessage := client.Blueprints(); errorMessage != nil {
+		t.Errorf("Only the next request should have failed: %v", errorMessage)
+	}
+}
+
+func TestDatasources(t *testing.T) {
+	_, client := newClient(t)
+
+	_, errorMessage := client.CreateBatch(map[string]string{"description": "No name"})
+	if !m2x.IsValidation(errorMessage) || errorMessage.StatusCode != 422 || len(errorMessage.FieldErrors()) != 2 {
+		t.Errorf("The server should have rejected the batch: %v", errorMessage)
+	}
+
+	batch, errorMessage := client.CreateBatchWithParams(&m2x.BatchParams{Name: "Gateways", Visibility: "public"})
+	if errorMessage != nil || batch.Feed != "/feeds/"+batch.ID || batch.Key == "" || batch.Created.IsZero() {
+		t.Fatalf("Did not create a batch properly: %v", errorMessage)
+	}
+	feed, errorMessage := client.Feed(batch.Feed)
+	if errorMessage != nil || feed.Type != "batch" || feed.Name != "Gateways" {
+		t.Errorf("A feed should have been created for the batch: %v", errorMessage)
+	}
+
+	if errorMessage := client.UpdateBatchWithParams(batch.ID, &m2x.BatchParams{Description: "Updated"}); errorMessage != nil {
+		t.Errorf("Did not update the batch properly: %v", errorMessage)
+	}
+	batch, _ = client.Batch(batch.ID)
+	if batch.Description != "Updated" || batch.Name != "Gateways" {
+		t.Errorf("Only the description should have been updated")
+	}
+
+	for i := 0; i < 11; i++ {
+		client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Sensor", Visibility: "private"})
+	}
+	blueprints, _ := client.BlueprintsPage(&m2x.ListOptions{Page: 2, Limit: 10})
+	if blueprints.Total != 11 || blueprints.Pages != 2 || blueprints.CurrentPage != 2 || len(blueprints.Blueprints) != 1 {
+		t.Errorf("Blueprints were not paged properly: %+v", blueprints)
+	}
+
+	if _, errorMessage := client.DeleteBatch(batch.ID); errorMessage != nil {
+		t.Errorf("Did not delete the batch properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.Feed(batch.Feed); !m2x.IsNotFound(errorMessage) || errorMessage.Message != "The specified feed does not exist" {
+		t.Errorf("The feed of the batch should have been deleted: %v", errorMessage)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	_, client := newClient(t)
+	blueprint, _ := client.CreateBlueprintWithParams(&m2x.BlueprintParams{Name: "Sensor", Visibility: "private"})
+
+	_, errorMessage := client.CreateKeyWithParams(&m2x.KeyParams{Name: "Bad", Permissions: []string{"GET"}, Feed: "/feeds/1234"})
+	if !m2x.IsValidation(errorMessage) || errorMessage.Errors["feed"] == nil {
+		t.Errorf("A key for a missing feed should have been rejected: %v", errorMessage)
+	}
+
+	key, _ := client.CreateKeyWithParams(&m2x.KeyParams{Name: "Writer", Permissions: []string{"POST"}, Feed: blueprint.Feed})
+	keys, errorMessage := client.SearchKeys(&m2x.KeyQuery{Feed: blueprint.Feed})
+	if errorMessage != nil || keys.Total != 2 {
+		t.Errorf("The blueprint key and the new key should be listed: %v", errorMessage)
+	}
+	if errorMessage := client.UpdateKeyWithParams(key.Key, &m2x.KeyParams{Name: "Renamed"}); errorMessage != nil {
+		t.Errorf("Did not update the key properly: %v", errorMessage)
+	}
+	if key, _ = client.Key(key.Key); key.Name != "Renamed" || key.Permissions[0] != "POST" {
+		t.Errorf("Only the name of the key should have been updated")
+	}
+	if errorMessage := client.DeleteKey(key.Key); errorMessage != nil {
+		t.Errorf("Did not delete the key properly: %v", errorMessage)
+	}
+	if _, errorMessage := client.Key(key.Key); errorMessage == nil || errorMessage.Message != "The specified key does not exist" {
+		t.Errorf("The key should have been deleted: %v", errorMessage)
+	}
+}
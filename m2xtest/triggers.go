@@ -0,0 +1,167 @@
+// Copyright (c) 2014 Jason Goecke
+// triggers.go
+
+package m2xtest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// A trigger of a feed
+type trigger struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Stream      string `json:"stream"`
+	Condition   string `json:"condition"`
+	Value       string `json:"value"`
+	CallbackURL string `json:"callback_url"`
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	Created     string `json:"created"`
+	Updated     string `json:"updated"`
+}
+
+// The fields accepted when creating or updating a trigger
+type triggerParams struct {
+	Name        *string `json:"name"`
+	Stream      *string `json:"stream"`
+	Condition   *string `json:"condition"`
+	Value       *text   `json:"value"`
+	CallbackURL *string `json:"callback_url"`
+	Status      *string `json:"status"`
+}
+
+// Serves the triggers of a feed
+func serveTriggers(w http.ResponseWriter, r *request, f *feed) {
+	if len(r.segments) == 3 {
+		if !r.allow(w, "GET", "POST") {
+			return
+		}
+		if r.Method == "GET" {
+			triggers := []interface{}{}
+			for _, id := range f.triggerOrder {
+				triggers = append(triggers, f.triggers[id])
+			}
+			writeJSON(w, 200, map[string]interface{}{"triggers": triggers})
+			return
+		}
+		createTrigger(w, r, f)
+		return
+	}
+
+	t, ok := f.triggers[r.segment(3)]
+	if !ok {
+		writeNotFound(w, "trigger")
+		return
+	}
+	if len(r.segments) == 5 {
+		if r.segment(4) != "test" {
+			writeMessage(w, 404, "Not Found")
+			return
+		}
+		if r.allow(w, "POST") {
+			w.WriteHeader(204)
+		}
+		return
+	}
+	if !r.allow(w, "GET", "PUT", "DELETE") {
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, 200, t)
+	case "PUT":
+		params := &triggerParams{}
+		if !r.decode(w, params) || !params.validate(f, false).check(w) {
+			return
+		}
+		params.apply(t)
+		t.Updated = formatTime(r.now)
+		w.WriteHeader(204)
+	case "DELETE":
+		delete(f.triggers, t.ID)
+		f.triggerOrder = remove(f.triggerOrder, t.ID)
+		w.WriteHeader(204)
+	}
+}
+
+// Creates a trigger on a stream of the feed
+func createTrigger(w http.ResponseWriter, r *request, f *feed) {
+	params := &triggerParams{}
+	if !r.decode(w, params) || !params.validate(f, true).check(w) {
+		return
+	}
+	id := newID()
+	t := &trigger{
+		ID:      id,
+		URL:     f.URL + "/triggers/" + id,
+		Status:  "enabled",
+		Created: formatTime(r.now),
+		Updated: formatTime(r.now),
+	}
+	params.apply(t)
+	f.triggers[id] = t
+	f.triggerOrder = append(f.triggerOrder, id)
+	writeJSON(w, 201, t)
+}
+
+// Checks the fields of a trigger, requiring all but the status on creation
+func (p *triggerParams) validate(f *feed, create bool) validation {
+	errs := validation{}
+	if create || p.Name != nil {
+		errs.require("name", value(p.Name))
+	}
+	if create || p.Stream != nil {
+		errs.require("stream", value(p.Stream))
+		if _, ok := f.streams[value(p.Stream)]; !ok && value(p.Stream) != "" {
+			errs.add("stream", "does not exist")
+		}
+	}
+	if create || p.Condition != nil {
+		errs.require("condition", value(p.Condition))
+	}
+	errs.oneOf("condition", value(p.Condition), "<", "<=", "=", ">", ">=")
+	if create || p.Value != nil {
+		threshold := ""
+		if p.Value != nil {
+			threshold = string(*p.Value)
+		}
+		errs.require("value", threshold)
+		if _, err := strconv.ParseFloat(threshold, 64); err != nil && threshold != "" {
+			errs.add("value", "is not a number")
+		}
+	}
+	if create || p.CallbackURL != nil {
+		errs.require("callback_url", value(p.CallbackURL))
+		callback, err := url.Parse(value(p.CallbackURL))
+		if value(p.CallbackURL) != "" && (err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "") {
+			errs.add("callback_url", "is not a valid URL")
+		}
+	}
+	errs.oneOf("status", value(p.Status), "enabled", "disabled")
+	return errs
+}
+
+// Sets the fields given in the params
+func (p *triggerParams) apply(t *trigger) {
+	if p.Name != nil {
+		t.Name = *p.Name
+	}
+	if p.Stream != nil {
+		t.Stream = *p.Stream
+	}
+	if p.Condition != nil {
+		t.Condition = *p.Condition
+	}
+	if p.Value != nil {
+		t.Value = string(*p.Value)
+	}
+	if p.CallbackURL != nil {
+		t.CallbackURL = *p.CallbackURL
+	}
+	if p.Status != nil && *p.Status != "" {
+		t.Status = *p.Status
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2014 Jason Goecke
+// handler.go
+
+// Package m2xwebhook receives the events POSTed by M2X triggers to their
+// callback URL. A Handler is a net/http handler decoding the events and
+// dispatching them to the callbacks registered for their feed, stream or
+// trigger.
+//
+//		hook := m2xwebhook.NewHandler()
+//		hook.Handle(m2xwebhook.Match{Stream: "temperature"}, func(ctx context.Context, event *m2x.TriggerEvent) error {
+//			log.Println(event.Name, event.Value)
+//			return nil
+//		})
+//		http.Handle("/streamEvent", hook)
+package m2xwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	m2x "github.com/jsgoecke/m2x-go"
+)
+
+// DefaultMaxBodySize is the size of the largest event a Handler accepts, in bytes
+const DefaultMaxBodySize = 64 << 10
+
+// Callback is called with each event matching the Match it was registered with.
+// An error makes the handler answer with a 500 status code.
+type Callback func(ctx context.Context, event *m2x.TriggerEvent) error
+
+// Match selects the events a callback is registered for. Empty fields match any
+// event, so the zero Match matches every event.
+type Match struct {
+	// Feed is the ID of the feed, which may also be given as "/feeds/<ID>"
+	Feed string
+	// Stream is the name of the stream
+	Stream string
+	// Trigger is the name of the trigger
+	Trigger string
+}
+
+// Reports whether event is selected by the match
+func (m Match) matches(event *m2x.TriggerEvent) bool {
+	feed := strings.TrimPrefix(m.Feed, "/feeds/")
+	return (feed == "" || feed == event.FeedID) &&
+		(m.Stream == "" || m.Stream == event.Stream) &&
+		(m.Trigger == "" || m.Trigger == event.Name)
+}
+
+// Option configures a Handler created with NewHandler
+type Option func(*Handler)
+
+// WithMaxBodySize makes the handler reject events larger than n bytes
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		h.maxBodySize = n
+	}
+}
+
+// WithErrorHandler makes the handler call handler with the error of every
+// request it did not answer with a 2xx status code
+func WithErrorHandler(handler func(r *http.Request, err error)) Option {
+	return func(h *Handler) {
+		h.onError = handler
+	}
+}
+
+// Handler is an http.Handler for the events of M2X triggers. It answers:
+//
+//		204 when the callbacks matching the event all succeeded
+//		400 when the body is not a valid event
+//		404 when no callback matches the event
+//		405 when the method is not POST
+//		413 when the body is larger than the maximum size
+//		415 when the content type is not application/json
+//		500 when a callback failed
+//
+// Callbacks may be registered while the handler serves requests.
+type Handler struct {
+	maxBodySize int64
+	onError     func(r *http.Request, err error)
+
+	mu        sync.RWMutex
+	callbacks []registration
+}
+
+// registration is a callback along with the events it was registered for
+type registration struct {
+	match    Match
+	callback Callback
+}
+
+// NewHandler creates a handler without any callback
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Handle registers callback for the events selected by match. Every matching
+// callback is called, in the order they were registered, even if an earlier
+// one failed.
+//
+//		hook.Handle(m2xwebhook.Match{Feed: blueprint.Feed, Trigger: "Too hot"}, alert)
+func (h *Handler) Handle(match Match, callback Callback) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callbacks = append(h.callbacks, registration{match: match, callback: callback})
+}
+
+// ServeHTTP decodes the event POSTed in the request and calls the matching callbacks
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		h.fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("m2xwebhook: method %s not allowed", r.Method))
+		return
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		h.fail(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("m2xwebhook: content type %q not supported", r.Header.Get("Content-Type")))
+		return
+	}
+	if r.ContentLength > h.maxBodySize {
+		h.fail(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("m2xwebhook: body of %d bytes too large", r.ContentLength))
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.fail(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("m2xwebhook: body larger than %d bytes", tooLarge.Limit))
+		} else {
+			h.fail(w, r, http.StatusBadRequest, fmt.Errorf("m2xwebhook: reading body: %v", err))
+		}
+		return
+	}
+
+	event, err := m2x.DecodeTriggerEvent(body)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("m2xwebhook: decoding event: %v", err))
+		return
+	}
+	if event.FeedID == "" || event.Stream == "" || event.Name == "" {
+		h.fail(w, r, http.StatusBadRequest, errors.New("m2xwebhook: event without feed_id, stream or trigger_name"))
+		return
+	}
+
+	h.mu.RLock()
+	var callbacks []Callback
+	for _, registration := range h.callbacks {
+		if registration.match.matches(event) {
+			callbacks = append(callbacks, registration.callback)
+		}
+	}
+	h.mu.RUnlock()
+	if len(callbacks) == 0 {
+		h.fail(w, r, http.StatusNotFound, fmt.Errorf("m2xwebhook: no callback for trigger %s of feed %s stream %s", event.Name, event.FeedID, event.Stream))
+		return
+	}
+
+	var failed error
+	for _, callback := range callbacks {
+		if err := callback(r.Context(), event); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	if failed != nil {
+		h.fail(w, r, http.StatusInternalServerError, failed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Answers a request with statusCode and reports err to the error handler
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	if h.onError != nil {
+		h.onError(r, err)
+	}
+	http.Error(w, http.StatusText(statusCode), statusCode)
+}
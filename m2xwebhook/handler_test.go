@@ -0,0 +1,136 @@
+// Copyright (c) 2014 Jason Goecke
+// handler_test.go
+
+package m2xwebhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	m2x "github.com/jsgoecke/m2x-go"
+	"github.com/jsgoecke/m2x-go/m2xwebhook"
+)
+
+const event = `{
+	"feed_id": "a65689ce7a9a69291c6ed2deda1affad",
+	"stream": "temperature",
+	"trigger_name": "Too hot",
+	"trigger_description": "temperature > 30",
+	"condition": ">",
+	"threshold": "30",
+	"value": 31.5,
+	"at": "2014-01-11T16:14:14Z"
+}`
+
+// Posts body to the handler and returns the status code of the response
+func post(handler http.Handler, contentType string, body string) int {
+	r := httptest.NewRequest("POST", "/streamEvent", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestHandlerDispatch(t *testing.T) {
+	var calls []string
+	record := func(name string) m2xwebhook.Callback {
+		return func(ctx context.Context, event *m2x.TriggerEvent) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	hook := m2xwebhook.NewHandler()
+	hook.Handle(m2xwebhook.Match{}, record("all"))
+	hook.Handle(m2xwebhook.Match{Feed: "/feeds/a65689ce7a9a69291c6ed2deda1affad"}, record("feed"))
+	hook.Handle(m2xwebhook.Match{Feed: "a65689ce7a9a69291c6ed2deda1affad", Stream: "temperature", Trigger: "Too hot"}, record("trigger"))
+	hook.Handle(m2xwebhook.Match{Stream: "humidity"}, record("humidity"))
+	hook.Handle(m2xwebhook.Match{Trigger: "Too cold"}, record("cold"))
+
+	if statusCode := post(hook, "application/json; charset=utf-8", event); statusCode != 204 {
+		t.Errorf("Expected a 204 status code, got %d", statusCode)
+	}
+	if strings.Join(calls, ",") != "all,feed,trigger" {
+		t.Errorf("The matching callbacks were not called in order: %v", calls)
+	}
+}
+
+func TestHandlerEvent(t *testing.T) {
+	var received *m2x.TriggerEvent
+	hook := m2xwebhook.NewHandler()
+	hook.Handle(m2xwebhook.Match{Stream: "temperature"}, func(ctx context.Context, event *m2x.TriggerEvent) error {
+		received = event
+		return nil
+	})
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(event))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 204 || received == nil {
+		t.Fatalf("The event was not received properly: %d", resp.StatusCode)
+	}
+	if value, _ := received.Value.Float64(); value != 31.5 || received.Threshold.String() != "30" || received.At.IsZero() {
+		t.Errorf("The event was not decoded properly: %+v", received)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	var errs []error
+	hook := m2xwebhook.NewHandler(m2xwebhook.WithMaxBodySize(512), m2xwebhook.WithErrorHandler(func(r *http.Request, err error) {
+		errs = append(errs, err)
+	}))
+	hook.Handle(m2xwebhook.Match{Trigger: "Too hot"}, func(ctx context.Context, event *m2x.TriggerEvent) error {
+		return errors.New("paging failed")
+	})
+
+	r := httptest.NewRequest("GET", "/streamEvent", nil)
+	w := httptest.NewRecorder()
+	hook.ServeHTTP(w, r)
+	if w.Code != 405 || w.Header().Get("Allow") != "POST" {
+		t.Errorf("A GET should not be allowed: %d", w.Code)
+	}
+
+	for _, test := range []struct {
+		contentType string
+		body        string
+		statusCode  int
+	}{
+		{"text/plain", event, 415},
+		{"", event, 415},
+		{"application/json", event + strings.Repeat(" ", 512), 413},
+		{"application/json", `{"feed_id": `, 400},
+		{"application/json", `{"feed_id": "1234", "value": true}`, 400},
+		{"application/json", `{"value": 31.5}`, 400},
+		{"application/json", strings.Replace(event, "Too hot", "Too cold", 1), 404},
+		{"application/json", event, 500},
+	} {
+		if statusCode := post(hook, test.contentType, test.body); statusCode != test.statusCode {
+			t.Errorf("Expected a %d status code for %q %.20q, got %d", test.statusCode, test.contentType, test.body, statusCode)
+		}
+	}
+	if len(errs) != 9 || errs[8].Error() != "paging failed" {
+		t.Errorf("The errors were not reported properly: %v", errs)
+	}
+}
+
+func TestHandlerChunkedBodySize(t *testing.T) {
+	hook := m2xwebhook.NewHandler(m2xwebhook.WithMaxBodySize(512))
+	hook.Handle(m2xwebhook.Match{}, func(ctx context.Context, event *m2x.TriggerEvent) error {
+		return nil
+	})
+	r := httptest.NewRequest("POST", "/streamEvent", strings.NewReader(event+strings.Repeat(" ", 512)))
+	r.Header.Set("Content-Type", "application/json")
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+	hook.ServeHTTP(w, r)
+	if w.Code != 413 {
+		t.Errorf("A body of unknown length larger than the maximum should be rejected: %d", w.Code)
+	}
+}
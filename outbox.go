@@ -0,0 +1,499 @@
+// Copyright (c) 2014 Jason Goecke
+// outbox.go
+
+package m2x
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultSegmentSize is the size at which an Outbox starts a new segment file
+	DefaultSegmentSize = 1 << 20
+	// DefaultMaxOutboxSize is the size above which an Outbox evicts its oldest
+	// segments
+	DefaultMaxOutboxSize = 64 << 20
+)
+
+// Each record is its payload length and CRC-32 followed by the payload
+const recordHeaderSize = 8
+
+// ErrOutboxClosed is returned when using an Outbox that has been closed
+var ErrOutboxClosed = errors.New("m2x: outbox closed")
+
+// OutboxEntry holds values of a stream waiting in an Outbox
+type OutboxEntry struct {
+	Feed   string  `json:"feed"`
+	Stream string  `json:"stream"`
+	Values []Value `json:"values"`
+}
+
+// OutboxOption configures an Outbox opened with OpenOutbox
+type OutboxOption func(*Outbox)
+
+// WithSegmentSize makes the outbox start a new segment file once the current
+// one reaches size bytes
+func WithSegmentSize(size int64) OutboxOption {
+	return func(o *Outbox) {
+		o.segmentSize = size
+	}
+}
+
+// WithMaxOutboxSize makes the outbox evict its oldest segments once its files
+// take more than size bytes
+func WithMaxOutboxSize(size int64) OutboxOption {
+	return func(o *Outbox) {
+		o.maxSize = size
+	}
+}
+
+// Outbox is a queue of stream values kept on disk, so values that could not be
+// posted survive losing connectivity and restarts. Entries are appended to
+// segment files in a directory and synced before Append returns. When the
+// outbox is opened again, an entry torn by a crash is discarded along with
+// anything after it in its segment, and the remaining entries are replayed by
+// Drain. Entries are delivered at least once: an entry passed to Drain just
+// before a crash is delivered again.
+//
+// Once the segments take more than the maximum size, whole segments are
+// evicted oldest first, so an outbox that is never drained keeps the most
+// recent values. An Outbox may be used from several goroutines, but a
+// directory must only be opened by one Outbox at a time.
+//
+//		outbox, err := m2x.OpenOutbox("/var/lib/gateway/outbox")
+//		writer := m2x.NewValueWriter(client, m2x.WithOutbox(outbox))
+type Outbox struct {
+	dir         string
+	segmentSize int64
+	maxSize     int64
+
+	mu       sync.Mutex
+	segments []*outboxSegment
+	file     *os.File
+	cursor   outboxCursor
+	evicted  int64
+
+	drainMu sync.Mutex
+}
+
+// A segment file, holding size bytes of valid records
+type outboxSegment struct {
+	seq     uint64
+	size    int64
+	records int
+}
+
+// The position of the oldest entry not yet drained, saved in the cursor file
+type outboxCursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+	Records int    `json:"records"`
+}
+
+// OpenOutbox opens the outbox kept in dir, creating the directory if needed and
+// recovering the entries left by a previous process
+func OpenOutbox(dir string, options ...OutboxOption) (*Outbox, error) {
+	o := &Outbox{
+		dir:         dir,
+		segmentSize: DefaultSegmentSize,
+		maxSize:     DefaultMaxOutboxSize,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if o.segmentSize <= 0 {
+		o.segmentSize = DefaultSegmentSize
+	}
+	if o.maxSize <= 0 {
+		o.maxSize = DefaultMaxOutboxSize
+	}
+	if o.segmentSize > o.maxSize {
+		o.segmentSize = o.maxSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := o.recover(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Pending returns the number of entries waiting to be drained
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending := 0
+	for _, segment := range o.segments {
+		pending += segment.records
+	}
+	return pending - o.cursor.Records
+}
+
+// Size returns the number of bytes taken by the segment files
+func (o *Outbox) Size() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.totalSize()
+}
+
+// Evicted returns the number of entries dropped to keep the outbox under its
+// maximum size since it was opened
+func (o *Outbox) Evicted() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.evicted
+}
+
+// Append adds values of a stream to the outbox, returning once they are on disk
+func (o *Outbox) Append(feed string, stream string, values []Value) error {
+	payload, err := json.Marshal(&OutboxEntry{Feed: feed, Stream: stream, Values: values})
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return ErrOutboxClosed
+	}
+	active := o.segments[len(o.segments)-1]
+	if active.size > 0 && active.size+int64(len(record)) > o.segmentSize {
+		if err := o.roll(); err != nil {
+			return err
+		}
+		active = o.segments[len(o.segments)-1]
+	}
+	if _, err := o.file.Write(record); err != nil {
+		// Drop whatever part of the record was written, so later records
+		// don't end up behind a torn one
+		o.file.Truncate(active.size)
+		return err
+	}
+	if err := o.file.Sync(); err != nil {
+		// The record may or may not have reached the disk, drop it so the
+		// offsets of later records stay right
+		o.file.Truncate(active.size)
+		return err
+	}
+	active.size += int64(len(record))
+	active.records++
+	return o.evict()
+}
+
+// Drain passes the entries of the outbox to fn, oldest first, removing each
+// one once fn returns nil. It stops at the first error from fn, which is
+// returned and leaves that entry at the head of the outbox.
+func (o *Outbox) Drain(fn func(*OutboxEntry) error) error {
+	o.drainMu.Lock()
+	defer o.drainMu.Unlock()
+	for {
+		entry, at, next, err := o.peek()
+		if err != nil || entry == nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+		if err := o.ack(at, next); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the current segment file. The entries stay on disk for the next
+// OpenOutbox.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return ErrOutboxClosed
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+// Loads the segments and cursor left in the directory, truncating torn
+// records, and opens the newest segment for appending
+func (o *Outbox) recover() error {
+	paths, err := filepath.Glob(filepath.Join(o.dir, "*.seg"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), ".seg"), 10, 64)
+		if err != nil {
+			continue
+		}
+		segment, err := scanSegment(path, seq)
+		if err != nil {
+			return err
+		}
+		o.segments = append(o.segments, segment)
+	}
+	sort.Slice(o.segments, func(i, j int) bool { return o.segments[i].seq < o.segments[j].seq })
+	if len(o.segments) == 0 {
+		o.segments = []*outboxSegment{{seq: 1}}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(o.dir, "cursor"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &o.cursor); err != nil {
+			o.cursor = outboxCursor{}
+		}
+	}
+	// Remove the segments drained before a crash, then make sure the cursor
+	// points into a segment that is still there, replaying it if in doubt
+	for len(o.segments) > 1 && o.segments[0].seq < o.cursor.Segment {
+		if err := os.Remove(o.segmentPath(o.segments[0].seq)); err != nil {
+			return err
+		}
+		o.segments = o.segments[1:]
+	}
+	first := o.segments[0]
+	if o.cursor.Segment != first.seq || o.cursor.Offset > first.size || o.cursor.Records > first.records {
+		o.cursor = outboxCursor{Segment: first.seq}
+	}
+
+	active := o.segments[len(o.segments)-1]
+	o.file, err = os.OpenFile(o.segmentPath(active.seq), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	return o.syncDir()
+}
+
+// Reads the valid records of a segment file, truncating it after the last one
+func scanSegment(path string, seq uint64) (*outboxSegment, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	segment := &outboxSegment{seq: seq}
+	for {
+		_, length, err := readRecord(file, info.Size()-segment.size)
+		if err != nil {
+			break
+		}
+		segment.size += length
+		segment.records++
+	}
+	if err := file.Truncate(segment.size); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// Reads the record at the current position of r, returning its payload and
+// its length including the header. remaining is the number of bytes left in
+// the segment, a larger record length meaning the header is corrupted.
+func readRecord(r io.Reader, remaining int64) ([]byte, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if int64(length) > remaining-recordHeaderSize {
+		return nil, 0, fmt.Errorf("m2x: outbox record length %d exceeds the %d bytes left in the segment", length, remaining)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, 0, errors.New("m2x: outbox record checksum mismatch")
+	}
+	return payload, int64(len(header) + len(payload)), nil
+}
+
+// Reads the oldest entry not yet drained, along with its position and the
+// position following it. Returns a nil entry when the outbox is empty.
+func (o *Outbox) peek() (*OutboxEntry, outboxCursor, outboxCursor, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil, o.cursor, o.cursor, ErrOutboxClosed
+	}
+	// Skip segments left empty or fully drained by an earlier process
+	for len(o.segments) > 1 && o.cursor.Offset >= o.segments[0].size {
+		if err := o.removeOldest(); err != nil {
+			return nil, o.cursor, o.cursor, err
+		}
+		if err := o.saveCursor(); err != nil {
+			return nil, o.cursor, o.cursor, err
+		}
+	}
+	at := o.cursor
+	if at.Offset >= o.segments[0].size {
+		return nil, at, at, nil
+	}
+	file, err := os.Open(o.segmentPath(at.Segment))
+	if err != nil {
+		return nil, at, at, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(at.Offset, io.SeekStart); err != nil {
+		return nil, at, at, err
+	}
+	payload, length, err := readRecord(file, o.segments[0].size-at.Offset)
+	if err != nil {
+		return nil, at, at, fmt.Errorf("m2x: reading outbox segment %d: %v", at.Segment, err)
+	}
+	entry := &OutboxEntry{}
+	if err := json.Unmarshal(payload, entry); err != nil {
+		return nil, at, at, err
+	}
+	next := outboxCursor{Segment: at.Segment, Offset: at.Offset + length, Records: at.Records + 1}
+	return entry, at, next, nil
+}
+
+// Moves the cursor past a drained entry, removing its segment once every
+// entry in it has been drained. Does nothing if the entry was evicted while
+// it was being drained.
+func (o *Outbox) ack(at outboxCursor, next outboxCursor) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cursor != at {
+		return nil
+	}
+	o.cursor = next
+	if next.Offset >= o.segments[0].size {
+		if len(o.segments) == 1 {
+			if err := o.roll(); err != nil {
+				return err
+			}
+		}
+		if err := o.removeOldest(); err != nil {
+			return err
+		}
+	}
+	return o.saveCursor()
+}
+
+// Closes the current segment and starts a new one. Must be called with o.mu held.
+func (o *Outbox) roll() error {
+	seq := o.segments[len(o.segments)-1].seq + 1
+	file, err := os.OpenFile(o.segmentPath(seq), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := o.syncDir(); err != nil {
+		file.Close()
+		os.Remove(o.segmentPath(seq))
+		return err
+	}
+	if err := o.file.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	o.file = file
+	o.segments = append(o.segments, &outboxSegment{seq: seq})
+	return nil
+}
+
+// Removes oldest segments until the outbox fits in its maximum size, always
+// keeping the current one. Must be called with o.mu held.
+func (o *Outbox) evict() error {
+	evicted := false
+	for len(o.segments) > 1 && o.totalSize() > o.maxSize {
+		o.evicted += int64(o.segments[0].records - o.cursor.Records)
+		if err := o.removeOldest(); err != nil {
+			return err
+		}
+		evicted = true
+	}
+	if evicted {
+		return o.saveCursor()
+	}
+	return nil
+}
+
+// Removes the oldest segment and points the cursor at the start of the next
+// one. Must be called with o.mu held.
+func (o *Outbox) removeOldest() error {
+	if err := os.Remove(o.segmentPath(o.segments[0].seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	o.segments = o.segments[1:]
+	o.cursor = outboxCursor{Segment: o.segments[0].seq}
+	return nil
+}
+
+// Writes the cursor file, replacing the previous one atomically. Must be
+// called with o.mu held.
+func (o *Outbox) saveCursor() error {
+	data, err := json.Marshal(&o.cursor)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(o.dir, "cursor")
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	return o.syncDir()
+}
+
+// Syncs the outbox directory, so the segment files created in it and the
+// cursor file renamed in it survive a crash
+func (o *Outbox) syncDir() error {
+	dir, err := os.Open(o.dir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// Returns the size of every segment. Must be called with o.mu held.
+func (o *Outbox) totalSize() int64 {
+	var size int64
+	for _, segment := range o.segments {
+		size += segment.size
+	}
+	return size
+}
+
+// Returns the path of a segment file
+func (o *Outbox) segmentPath(seq uint64) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%020d.seg", seq))
+}
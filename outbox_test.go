@@ -0,0 +1,161 @@
+// Copyright (c) 2014 Jason Goecke
+// outbox_test.go
+
+package m2x
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns the values of the entries drained from the outbox, as strings
+func drainAll(t *testing.T, outbox *Outbox) []string {
+	var drained []string
+	err := outbox.Drain(func(entry *OutboxEntry) error {
+		for _, value := range entry.Values {
+			drained = append(drained, entry.Stream+"="+value.Value.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Draining the outbox failed: %v", err)
+	}
+	return drained
+}
+
+func outboxValue(i int64) []Value {
+	return []Value{{At: NewTimestamp(time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)), Value: Int64Value(i)}}
+}
+
+func TestOutboxReplay(t *testing.T) {
+	dir := t.TempDir()
+	outbox, err := OpenOutbox(dir, WithSegmentSize(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(0); i < 10; i++ {
+		if err := outbox.Append("/feeds/1234", "temperature", outboxValue(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if outbox.Pending() != 10 {
+		t.Errorf("Expected 10 pending entries, got %d", outbox.Pending())
+	}
+
+	// Drain some entries, then reopen the outbox as a restarted process would
+	stop := errors.New("offline")
+	drained := 0
+	err = outbox.Drain(func(entry *OutboxEntry) error {
+		if drained == 4 {
+			return stop
+		}
+		drained++
+		return nil
+	})
+	if err != stop || outbox.Pending() != 6 {
+		t.Errorf("Draining should stop at the first error: %v, %d pending", err, outbox.Pending())
+	}
+	outbox.Close()
+	if err := outbox.Append("/feeds/1234", "temperature", outboxValue(10)); err != ErrOutboxClosed {
+		t.Errorf("Appending to a closed outbox should fail")
+	}
+
+	outbox, err = OpenOutbox(dir, WithSegmentSize(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	values := drainAll(t, outbox)
+	if len(values) != 6 || values[0] != "temperature=4" || values[5] != "temperature=9" {
+		t.Errorf("The remaining entries were not replayed in order: %v", values)
+	}
+	if outbox.Pending() != 0 || outbox.Size() != 0 {
+		t.Errorf("A drained outbox should be empty: %d entries, %d bytes", outbox.Pending(), outbox.Size())
+	}
+	if segments, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(segments) != 1 {
+		t.Errorf("Drained segments should have been removed: %v", segments)
+	}
+}
+
+func TestOutboxTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	outbox, err := OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.Append("/feeds/1234", "temperature", outboxValue(1))
+	outbox.Append("/feeds/1234", "temperature", outboxValue(2))
+	size := outbox.Size()
+	outbox.Close()
+
+	// Simulate a crash in the middle of writing a third record
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	file, _ := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0600)
+	file.Write([]byte{0, 0, 0, 40, 1, 2, 3, 4, '{', '"'})
+	file.Close()
+
+	outbox, err = OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	if outbox.Pending() != 2 || outbox.Size() != size {
+		t.Errorf("The torn record should have been discarded: %d entries, %d bytes", outbox.Pending(), outbox.Size())
+	}
+	outbox.Append("/feeds/1234", "temperature", outboxValue(3))
+	if values := drainAll(t, outbox); len(values) != 3 || values[2] != "temperature=3" {
+		t.Errorf("Records appended after recovery were not kept: %v", values)
+	}
+}
+
+func TestOutboxCorruptedLength(t *testing.T) {
+	record := []byte{0xff, 0xff, 0xff, 0xf0, 1, 2, 3, 4, '{', '}'}
+	// Reading the payload would allocate 4 GiB
+	if _, _, err := readRecord(bytes.NewReader(record), int64(len(record))); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("A length past the end of the segment should be reported as corrupted: %v", err)
+	}
+
+	dir := t.TempDir()
+	outbox, err := OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.Append("/feeds/1234", "temperature", outboxValue(1))
+	outbox.Close()
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	file, _ := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0600)
+	file.Write(record)
+	file.Close()
+
+	outbox, err = OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	if values := drainAll(t, outbox); len(values) != 1 || values[0] != "temperature=1" {
+		t.Errorf("The corrupted record should have been discarded: %v", values)
+	}
+}
+
+func TestOutboxEviction(t *testing.T) {
+	outbox, err := OpenOutbox(t.TempDir(), WithSegmentSize(200), WithMaxOutboxSize(600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	for i := int64(0); i < 50; i++ {
+		outbox.Append("/feeds/1234", "temperature", outboxValue(i))
+	}
+	if outbox.Size() > 600 || outbox.Evicted() == 0 {
+		t.Errorf("The outbox should have stayed under its maximum size: %d bytes, %d evicted", outbox.Size(), outbox.Evicted())
+	}
+	values := drainAll(t, outbox)
+	if int64(len(values))+outbox.Evicted() != 50 || values[len(values)-1] != "temperature=49" {
+		t.Errorf("The oldest entries should have been evicted: %v", values)
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2014 Jason Goecke
+// pagination.go
+
+package m2x
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions selects a page of a collection of feeds, blueprints, batches or keys
+type ListOptions struct {
+	// Page is the page to get, starting at 1
+	Page int
+	// Limit is the number of items per page
+	Limit int
+	// Prefetch makes iterators fetch the next page while the current one is
+	// being consumed. It is not sent to the API.
+	Prefetch bool
+}
+
+// Returns the query string for the options, starting with "?" if not empty
+func (opts *ListOptions) query() string {
+	if params := opts.values(); len(params) > 0 {
+		return "?" + params.Encode()
+	}
+	return ""
+}
+
+// Encodes the options as query parameters
+func (opts *ListOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Page > 0 {
+		params.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	return params
+}
+
+// pageResult is a page fetched by a pager, holding a slice of the items along
+// with the pagination fields of the response
+type pageResult struct {
+	items interface{}
+	count int
+	pages int
+	total int
+	limit int
+	err   *ErrorMessage
+}
+
+// pager fetches the pages of a collection one after another, optionally
+// fetching the next page in the background
+type pager struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	fetch    func(ctx context.Context, page int) pageResult
+	page     int
+	limit    int
+	prefetch bool
+	pending  chan pageResult
+	done     bool
+	closed   bool
+	err      *ErrorMessage
+}
+
+// Creates a pager starting at the page given in opts
+func newPager(ctx context.Context, opts *ListOptions, fetch func(ctx context.Context, page int) pageResult) pager {
+	ctx, cancel := context.WithCancel(ctx)
+	p := pager{ctx: ctx, cancel: cancel, fetch: fetch, page: 1}
+	if opts != nil {
+		if opts.Page > 0 {
+			p.page = opts.Page
+		}
+		p.limit = opts.Limit
+		p.prefetch = opts.Prefetch
+	}
+	return p
+}
+
+// Returns the next page, or false once the collection is exhausted, the
+// context is done or a request failed
+func (p *pager) nextPage() (pageResult, bool) {
+	if p.done {
+		return pageResult{}, false
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.stop(simpleErrorMessage(err, 0))
+		return pageResult{}, false
+	}
+
+	var result pageResult
+	if p.pending != nil {
+		select {
+		case result = <-p.pending:
+		case <-p.ctx.Done():
+			result = pageResult{err: simpleErrorMessage(p.ctx.Err(), 0)}
+		}
+		p.pending = nil
+	} else {
+		result = p.fetch(p.ctx, p.page)
+	}
+	if result.err != nil {
+		p.stop(result.err)
+		return pageResult{}, false
+	}
+
+	p.page++
+	if p.last(result) {
+		p.stop(nil)
+	} else if p.prefetch {
+		p.pending = make(chan pageResult, 1)
+		go func(ctx context.Context, page int, pending chan<- pageResult) {
+			pending <- p.fetch(ctx, page)
+		}(p.ctx, p.page, p.pending)
+	}
+	return result, true
+}
+
+// Reports whether a page is the last one of the collection. Responses without
+// a number of pages fall back to the total and the limit, and without those to
+// fetching pages until a short or empty one.
+func (p *pager) last(result pageResult) bool {
+	if result.count == 0 {
+		return true
+	}
+	limit := result.limit
+	if limit <= 0 {
+		limit = p.limit
+	}
+	pages := result.pages
+	if pages <= 0 && result.total > 0 && limit > 0 {
+		pages = (result.total + limit - 1) / limit
+	}
+	if pages > 0 {
+		return p.page > pages
+	}
+	return limit > 0 && result.count < limit
+}
+
+// Stops the pager, recording the error that stopped it if any
+func (p *pager) stop(err *ErrorMessage) {
+	p.done = true
+	p.err = err
+	p.cancel()
+}
+
+// Close stops the iteration and cancels any page being prefetched. It should
+// be called when leaving an iteration early.
+func (p *pager) Close() {
+	p.closed = true
+	if !p.done {
+		p.stop(nil)
+	}
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *pager) Err() *ErrorMessage {
+	return p.err
+}
+
+// FeedIterator walks through every feed, fetching pages as needed
+//
+//		feeds := client.IterateFeeds(ctx, &ListOptions{Limit: 50})
+//		defer feeds.Close()
+//		for feeds.Next() {
+//			log.Println(feeds.Feed().Name)
+//		}
+//		if err := feeds.Err(); err != nil {
+//			log.Println(err)
+//		}
+type FeedIterator struct {
+	pager
+	feeds   []Feed
+	current Feed
+}
+
+// IterateFeeds returns an iterator over all the feeds, starting at the page
+// given in opts
+func (c *Client) IterateFeeds(ctx context.Context, opts *ListOptions) *FeedIterator {
+	return &FeedIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		feeds, err := c.FeedsPageContext(ctx, atPage(opts, page))
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: feeds.Feeds, count: len(feeds.Feeds), pages: feeds.Pages, total: feeds.Total, limit: feeds.Limit}
+	})}
+}
+
+// IterateFeedSearch returns an iterator over all the feeds matching the query,
+// starting at the page given in its ListOptions
+//
+//		feeds := client.IterateFeedSearch(ctx, &FeedQuery{Type: "batch", Tags: []string{"warehouse"}})
+func (c *Client) IterateFeedSearch(ctx context.Context, query *FeedQuery) *FeedIterator {
+	var opts *ListOptions
+	if query != nil {
+		opts = &query.ListOptions
+	}
+	return &FeedIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		pageQuery := FeedQuery{}
+		if query != nil {
+			pageQuery = *query
+		}
+		pageQuery.ListOptions = *atPage(opts, page)
+		feeds, err := c.SearchFeedsContext(ctx, &pageQuery)
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: feeds.Feeds, count: len(feeds.Feeds), pages: feeds.Pages, total: feeds.Total, limit: feeds.Limit}
+	})}
+}
+
+// Next advances to the next feed, returning false when there are no more
+// feeds or an error occurred
+func (it *FeedIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	for len(it.feeds) == 0 {
+		page, ok := it.nextPage()
+		if !ok {
+			return false
+		}
+		it.feeds = page.items.([]Feed)
+	}
+	it.current, it.feeds = it.feeds[0], it.feeds[1:]
+	return true
+}
+
+// Feed returns the current feed
+func (it *FeedIterator) Feed() Feed {
+	return it.current
+}
+
+// BlueprintIterator walks through every blueprint, fetching pages as needed
+type BlueprintIterator struct {
+	pager
+	blueprints []Blueprint
+	current    Blueprint
+}
+
+// IterateBlueprints returns an iterator over all the blueprints, starting at
+// the page given in opts
+func (c *Client) IterateBlueprints(ctx context.Context, opts *ListOptions) *BlueprintIterator {
+	return &BlueprintIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		blueprints, err := c.BlueprintsPageContext(ctx, atPage(opts, page))
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: blueprints.Blueprints, count: len(blueprints.Blueprints), pages: blueprints.Pages, total: blueprints.Total, limit: blueprints.Limit}
+	})}
+}
+
+// Next advances to the next blueprint, returning false when there are no
+// more blueprints or an error occurred
+func (it *BlueprintIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	for len(it.blueprints) == 0 {
+		page, ok := it.nextPage()
+		if !ok {
+			return false
+		}
+		it.blueprints = page.items.([]Blueprint)
+	}
+	it.current, it.blueprints = it.blueprints[0], it.blueprints[1:]
+	return true
+}
+
+// Blueprint returns the current blueprint
+func (it *BlueprintIterator) Blueprint() Blueprint {
+	return it.current
+}
+
+// BatchIterator walks through every batch, fetching pages as needed
+type BatchIterator struct {
+	pager
+	batches []Batch
+	current Batch
+}
+
+// IterateBatches returns an iterator over all the batches, starting at the
+// page given in opts
+func (c *Client) IterateBatches(ctx context.Context, opts *ListOptions) *BatchIterator {
+	return &BatchIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		batches, err := c.BatchesPageContext(ctx, atPage(opts, page))
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: batches.Batches, count: len(batches.Batches), pages: batches.Pages, total: batches.Total, limit: batches.Limit}
+	})}
+}
+
+// Next advances to the next batch, returning false when there are no more
+// batches or an error occurred
+func (it *BatchIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	for len(it.batches) == 0 {
+		page, ok := it.nextPage()
+		if !ok {
+			return false
+		}
+		it.batches = page.items.([]Batch)
+	}
+	it.current, it.batches = it.batches[0], it.batches[1:]
+	return true
+}
+
+// Batch returns the current batch
+func (it *BatchIterator) Batch() Batch {
+	return it.current
+}
+
+// KeyIterator walks through every key, fetching pages as needed
+type KeyIterator struct {
+	pager
+	keys    []Key
+	current Key
+}
+
+// IterateKeys returns an iterator over all the keys, starting at the page
+// given in opts
+func (c *Client) IterateKeys(ctx context.Context, opts *ListOptions) *KeyIterator {
+	return &KeyIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		keys, err := c.KeysPageContext(ctx, atPage(opts, page))
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: keys.Keys, count: len(keys.Keys), pages: keys.Pages, total: keys.Total, limit: keys.Limit}
+	})}
+}
+
+// IterateKeySearch returns an iterator over all the keys matching the query,
+// starting at the page given in its ListOptions
+//
+//		keys := client.IterateKeySearch(ctx, &KeyQuery{Feed: "1234"})
+func (c *Client) IterateKeySearch(ctx context.Context, query *KeyQuery) *KeyIterator {
+	var opts *ListOptions
+	if query != nil {
+		opts = &query.ListOptions
+	}
+	return &KeyIterator{pager: newPager(ctx, opts, func(ctx context.Context, page int) pageResult {
+		pageQuery := KeyQuery{}
+		if query != nil {
+			pageQuery = *query
+		}
+		pageQuery.ListOptions = *atPage(opts, page)
+		keys, err := c.SearchKeysContext(ctx, &pageQuery)
+		if err != nil {
+			return pageResult{err: err}
+		}
+		return pageResult{items: keys.Keys, count: len(keys.Keys), pages: keys.Pages, total: keys.Total, limit: keys.Limit}
+	})}
+}
+
+// Next advances to the next key, returning false when there are no more keys
+// or an error occurred
+func (it *KeyIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	for len(it.keys) == 0 {
+		page, ok := it.nextPage()
+		if !ok {
+			return false
+		}
+		it.keys = page.items.([]Key)
+	}
+	it.current, it.keys = it.keys[0], it.keys[1:]
+	return true
+}
+
+// Key returns the current key
+func (it *KeyIterator) Key() Key {
+	return it.current
+}
+
+// Sets a query parameter unless its value is empty
+func setParam(params url.Values, name string, value string) {
+	if value != "" {
+		params.Set(name, value)
+	}
+}
+
+// Copies the options for the given page
+func atPage(opts *ListOptions, page int) *ListOptions {
+	pageOpts := ListOptions{Page: page}
+	if opts != nil {
+		pageOpts.Limit = opts.Limit
+	}
+	return &pageOpts
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2014 Jason Goecke
+// pagination_test.go
+
+package m2x
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Serves 7 feeds, blueprints, batches and keys in pages of the requested limit
+func newPagedServer(t *testing.T) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		mu.Unlock()
+		total := 7
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if page == 0 {
+			page = 1
+		}
+		if limit == 0 {
+			limit = 10
+		}
+		pages := (total + limit - 1) / limit
+		var items []string
+		for i := (page-1)*limit + 1; i <= total && i <= page*limit; i++ {
+			items = append(items, fmt.Sprintf(`{"id":"%d","name":"item %d"}`, i, i))
+		}
+		collection := strings.TrimPrefix(r.URL.Path, "/")
+		fmt.Fprintf(w, `{"%s":[%s],"total":%d,"pages":%d,"limit":%d,"current_page":%d}`,
+			collection, strings.Join(items, ","), total, pages, limit, page)
+	}))
+	return server, &requests
+}
+
+func TestFeedsPage(t *testing.T) {
+	server, requests := newPagedServer(t)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	feeds, err := client.FeedsPage(&ListOptions{Page: 2, Limit: 3})
+	if err != nil || feeds.CurrentPage != 2 || len(feeds.Feeds) != 3 || feeds.Feeds[0].ID != "4" {
+		t.Errorf("Did not fetch the second page of feeds properly")
+	}
+	if (*requests)[0] != "/feeds?limit=3&page=2" {
+		t.Errorf("Page options were not sent properly: %s", (*requests)[0])
+	}
+}
+
+func TestIterators(t *testing.T) {
+	server, requests := newPagedServer(t)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	for _, prefetch := range []bool{false, true} {
+		*requests = nil
+		opts := &ListOptions{Limit: 3, Prefetch: prefetch}
+
+		var ids []string
+		feeds := client.IterateFeeds(context.Background(), opts)
+		for feeds.Next() {
+			ids = append(ids, feeds.Feed().ID)
+		}
+		if feeds.Err() != nil || strings.Join(ids, ",") != "1,2,3,4,5,6,7" {
+			t.Errorf("Did not iterate over all the feeds: %v %v", ids, feeds.Err())
+		}
+		if len(*requests) != 3 {
+			t.Errorf("Expected 3 pages to be fetched, got %v", *requests)
+		}
+
+		count := 0
+		blueprints := client.IterateBlueprints(context.Background(), opts)
+		for blueprints.Next() {
+			count++
+		}
+		batches := client.IterateBatches(context.Background(), opts)
+		for batches.Next() {
+			count++
+		}
+		keys := client.IterateKeys(context.Background(), opts)
+		for keys.Next() {
+			count++
+		}
+		if count != 21 {
+			t.Errorf("Did not iterate over all the blueprints, batches and keys: %d", count)
+		}
+	}
+}
+
+func TestIteratorEarlyTermination(t *testing.T) {
+	server, requests := newPagedServer(t)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	feeds := client.IterateFeeds(context.Background(), &ListOptions{Page: 2, Limit: 2})
+	feeds.Next()
+	if feeds.Feed().ID != "3" {
+		t.Errorf("Iteration did not start at the requested page")
+	}
+	feeds.Close()
+	if feeds.Next() || feeds.Err() != nil {
+		t.Errorf("A closed iterator should stop without an error")
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected a single page to be fetched, got %v", *requests)
+	}
+}
+
+func TestIteratorCancellation(t *testing.T) {
+	server, _ := newPagedServer(t)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := client.IterateKeys(ctx, &ListOptions{Limit: 2})
+	count := 0
+	for keys.Next() {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	}
+	if count != 4 || !errors.Is(keys.Err(), context.Canceled) {
+		t.Errorf("Iteration did not stop at the end of the page once cancelled: %d %v", count, keys.Err())
+	}
+}
+
+func TestIteratorError(t *testing.T) {
+	server, client, _ := newRecordingServer(401, `{"message":"Unauthorized"}`)
+	defer server.Close()
+
+	batches := client.IterateBatches(context.Background(), nil)
+	if batches.Next() || !IsUnauthorized(batches.Err()) {
+		t.Errorf("The API error was not reported: %v", batches.Err())
+	}
+}
+
+func TestIteratorWithoutPages(t *testing.T) {
+	for _, test := range []struct {
+		fields   string
+		limit    int
+		requests int
+	}{
+		// Falls back to the total and the limit
+		{`"total":7,"pages":0,"limit":3`, 3, 3},
+		// Falls back to a short page
+		{`"pages":0,"limit":3`, 3, 3},
+		{`"pages":0`, 3, 3},
+		// Falls back to an empty page
+		{`"pages":0`, 0, 4},
+	} {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			var items []string
+			for i := (page-1)*3 + 1; i <= 7 && i <= page*3; i++ {
+				items = append(items, fmt.Sprintf(`{"id":"%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"feeds":[%s],%s}`, strings.Join(items, ","), test.fields)
+		}))
+		client := NewClient("1234")
+		client.APIBase = server.URL
+
+		var ids []string
+		feeds := client.IterateFeeds(context.Background(), &ListOptions{Limit: test.limit})
+		for feeds.Next() {
+			ids = append(ids, feeds.Feed().ID)
+		}
+		if feeds.Err() != nil || strings.Join(ids, ",") != "1,2,3,4,5,6,7" || requests != test.requests {
+			t.Errorf("Did not iterate over all the feeds with %s: %v after %d requests %v", test.fields, ids, requests, feeds.Err())
+		}
+		server.Close()
+	}
+}
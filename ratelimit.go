@@ -0,0 +1,157 @@
+// Copyright (c) 2014 Jason Goecke
+// ratelimit.go
+
+package m2x
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups API requests so they can be rate limited separately
+type EndpointClass int
+
+const (
+	// ReadRequests are GET requests on any resource
+	ReadRequests EndpointClass = iota
+	// WriteRequests are requests creating, updating or deleting resources
+	WriteRequests
+	// ValueWrites are requests posting stream values, to a single stream or to
+	// several streams of a feed
+	ValueWrites
+)
+
+// RateLimiter is a token bucket limiting the rate of requests sent to the API.
+// Requests over the limit block until a token is available rather than fail.
+// A RateLimiter may be shared between several clients.
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	requests int64
+	waits    int64
+	waited   time.Duration
+}
+
+// RateLimiterStats reports how much a RateLimiter has delayed its callers
+type RateLimiterStats struct {
+	Requests int64
+	Waits    int64
+	WaitTime time.Duration
+}
+
+// NewRateLimiter creates a RateLimiter allowing requestsPerSecond on average,
+// with bursts of up to burst requests. A rate of zero or less does not limit.
+//
+//		limiter := NewRateLimiter(10, 20)
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimiter limits every request sent by the client with limiter
+//
+//		client := NewClient("<API-KEY>", WithRateLimiter(NewRateLimiter(10, 20)))
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithEndpointRateLimiter limits the requests of the given class with limiter
+// instead of the client wide one
+//
+//		client := NewClient("<API-KEY>", WithEndpointRateLimiter(ValueWrites, NewRateLimiter(5, 5)))
+func WithEndpointRateLimiter(class EndpointClass, limiter *RateLimiter) Option {
+	return func(c *Client) {
+		if c.EndpointRateLimiters == nil {
+			c.EndpointRateLimiters = make(map[EndpointClass]*RateLimiter)
+		}
+		c.EndpointRateLimiters[class] = limiter
+	}
+}
+
+// Wait blocks until the limiter allows one more request or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.requests++
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.waits++
+	l.mu.Unlock()
+
+	err := sleepContext(ctx, delay)
+	l.mu.Lock()
+	l.waited += time.Since(now)
+	if err != nil {
+		// Hand the reserved token back to the callers still waiting
+		l.tokens++
+	}
+	l.mu.Unlock()
+	return err
+}
+
+// Stats returns the number of requests seen by the limiter, how many of them
+// had to wait and the total time spent waiting
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return RateLimiterStats{
+		Requests: l.requests,
+		Waits:    l.waits,
+		WaitTime: l.waited,
+	}
+}
+
+// Adds the tokens accumulated since the last request
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// Returns the limiter applying to the request, if any
+func (c *Client) limiterFor(req *http.Request) *RateLimiter {
+	if limiter, ok := c.EndpointRateLimiters[endpointClass(req)]; ok {
+		return limiter
+	}
+	return c.RateLimiter
+}
+
+// Classifies a request by its method and path
+func endpointClass(req *http.Request) EndpointClass {
+	switch {
+	case req.Method == "GET" || req.Method == "HEAD":
+		return ReadRequests
+	case req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/values"):
+		return ValueWrites
+	case req.Method == "POST" && path.Base(path.Dir(req.URL.Path)) == "feeds":
+		return ValueWrites
+	}
+	return WriteRequests
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2014 Jason Goecke
+// ratelimit_test.go
+
+package m2x
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(1000, 5)
+	for i := 0; i < 5; i++ {
+		limiter.Wait(context.Background())
+	}
+	stats := limiter.Stats()
+	if stats.Requests != 5 || stats.Waits != 0 {
+		t.Errorf("A burst should not have waited: %+v", stats)
+	}
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		limiter.Wait(context.Background())
+	}
+	if time.Since(start) < 8*time.Millisecond {
+		t.Errorf("Requests over the burst were not delayed")
+	}
+	stats = limiter.Stats()
+	if stats.Requests != 15 || stats.Waits == 0 || stats.WaitTime <= 0 {
+		t.Errorf("Waits were not counted: %+v", stats)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait did not honor the context: %v", err)
+	}
+}
+
+func TestEndpointRateLimiters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(202)
+		}
+	}))
+	defer server.Close()
+
+	all := NewRateLimiter(1000, 100)
+	values := NewRateLimiter(1000, 100)
+	client := NewClient("1234", WithRateLimiter(all), WithEndpointRateLimiter(ValueWrites, values))
+	client.APIBase = server.URL
+
+	client.Feeds()
+	client.FeedStream("/feeds/1234", "temperature")
+	client.UpdateFeedLocation("/feeds/1234", map[string]interface{}{"name": "Storage Room"})
+	client.UpdateFeedStreamValues("/feeds/1234", "temperature", map[string]interface{}{"values": []Value{}})
+	client.UpdateFeedValues("/feeds/1234", NewFeedValues().Add("temperature", time.Now(), Int64Value(32)))
+	if all.Stats().Requests != 3 || values.Stats().Requests != 2 {
+		t.Errorf("Requests were not limited by their endpoint class: %+v %+v", all.Stats(), values.Stats())
+	}
+}
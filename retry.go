@@ -0,0 +1,156 @@
+// Copyright (c) 2014 Jason Goecke
+// retry.go
+
+package m2x
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that failed because of
+// connection errors, throttling (429) or server errors (5xx)
+//
+//		client := NewClient("<API-KEY>", WithRetryPolicy(DefaultRetryPolicy))
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled for each further retry
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts computed from MinBackoff.
+	// Zero leaves it uncapped.
+	MaxBackoff time.Duration
+	// MaxRetryAfter caps the delay the API may ask for with a Retry-After
+	// header. Zero means DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+	// RetryNonIdempotent allows POST requests, such as posting stream values,
+	// to be retried. The API may then receive the same data more than once.
+	RetryNonIdempotent bool
+}
+
+// DefaultMaxRetryAfter is the longest delay honored from a Retry-After header
+// when the policy does not set MaxRetryAfter
+const DefaultMaxRetryAfter = time.Minute
+
+// DefaultRetryPolicy retries idempotent requests up to three times in total
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  250 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// WithRetryPolicy makes the client retry failed requests according to policy
+//
+//		policy := DefaultRetryPolicy
+//		policy.RetryNonIdempotent = true
+//		client := NewClient("<API-KEY>", WithRetryPolicy(policy))
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = &policy
+	}
+}
+
+// Whether requests with the given method may be retried under the policy
+func (p *RetryPolicy) allows(method string) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// Computes the delay to wait after the given attempt, honoring a Retry-After
+// header when the API sent one, up to MaxRetryAfter
+func (p *RetryPolicy) backoff(attempt int, header http.Header) time.Duration {
+	if delay, ok := retryAfter(header, time.Now()); ok {
+		limit := p.MaxRetryAfter
+		if limit <= 0 {
+			limit = DefaultMaxRetryAfter
+		}
+		if delay > limit {
+			return limit
+		}
+		return delay
+	}
+	delay := p.MinBackoff
+	for i := 1; i < attempt && delay < math.MaxInt64/2 && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter: wait between half and all of the computed delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// Parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Whether the outcome of an attempt warrants another one
+func retryable(ctx context.Context, statusCode int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch statusCode {
+	case 429, 500, 502, 503, 504:
+		return true
+	}
+	return false
+}
+
+// Prepares a copy of the request with a fresh body for another attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// Waits for the given delay unless the context is done first
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
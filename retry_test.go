@@ -0,0 +1,161 @@
+// Copyright (c) 2014 Jason Goecke
+// retry_test.go
+
+package m2x
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32, failStatus int, okStatus int) (*httptest.Server, *int32) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			w.WriteHeader(failStatus)
+			w.Write([]byte(`{"message":"Service unavailable"}`))
+			return
+		}
+		w.WriteHeader(okStatus)
+		w.Write([]byte(`{"feeds":[],"current_page":1}`))
+	}))
+	return server, &attempts
+}
+
+var fastRetries = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestRetryOnServerError(t *testing.T) {
+	server, attempts := newFlakyServer(2, 503, 200)
+	defer server.Close()
+
+	client := NewClient("1234", WithRetryPolicy(fastRetries))
+	client.APIBase = server.URL
+	feeds, err := client.Feeds()
+	if err != nil || feeds.CurrentPage != 1 {
+		t.Errorf("Feeds were not fetched after retrying: %v", err)
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, attempts := newFlakyServer(10, 429, 200)
+	defer server.Close()
+
+	client := NewClient("1234", WithRetryPolicy(fastRetries))
+	client.APIBase = server.URL
+	_, err := client.Feeds()
+	if err == nil || err.StatusCode != 429 {
+		t.Errorf("Expected the last 429 to be returned, got %v", err)
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", *attempts)
+	}
+}
+
+func TestNoRetryWithoutPolicy(t *testing.T) {
+	server, attempts := newFlakyServer(1, 503, 200)
+	defer server.Close()
+
+	client := NewClient("1234")
+	client.APIBase = server.URL
+	if _, err := client.Feeds(); err == nil || err.StatusCode != 503 {
+		t.Errorf("Expected the 503 to be returned, got %v", err)
+	}
+	if *attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", *attempts)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	server, attempts := newFlakyServer(1, 502, 202)
+	defer server.Close()
+
+	client := NewClient("1234", WithRetryPolicy(fastRetries))
+	client.APIBase = server.URL
+	values := map[string]interface{}{"values": []map[string]string{{"at": "2013-09-09T19:15:00Z", "value": "32"}}}
+	if err := client.UpdateFeedStreamValues("/feeds/1234", "temperature", values); err == nil || err.StatusCode != 502 {
+		t.Errorf("POST should not have been retried, got %v", err)
+	}
+	if *attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", *attempts)
+	}
+
+	policy := fastRetries
+	policy.RetryNonIdempotent = true
+	atomic.StoreInt32(attempts, 0)
+	client = NewClient("1234", WithRetryPolicy(policy))
+	client.APIBase = server.URL
+	if err := client.UpdateFeedStreamValues("/feeds/1234", "temperature", values); err != nil {
+		t.Errorf("POST should have been retried, got %v", err)
+	}
+	if *attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryStopsOnContext(t *testing.T) {
+	server, _ := newFlakyServer(10, 503, 200)
+	defer server.Close()
+
+	client := NewClient("1234", WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MinBackoff: time.Minute, MaxBackoff: time.Minute}))
+	client.APIBase = server.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.FeedsContext(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the backoff to be interrupted by the context, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2014, 1, 13, 14, 35, 23, 0, time.UTC)
+	header := http.Header{}
+	if _, ok := retryAfter(header, now); ok {
+		t.Errorf("A missing Retry-After should be ignored")
+	}
+	header.Set("Retry-After", "7")
+	if delay, ok := retryAfter(header, now); !ok || delay != 7*time.Second {
+		t.Errorf("Retry-After in seconds did not parse properly")
+	}
+	header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	if delay, ok := retryAfter(header, now); !ok || delay != time.Minute {
+		t.Errorf("Retry-After as a date did not parse properly")
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	header.Set("Retry-After", "2")
+	if delay := policy.backoff(1, header); delay != 2*time.Second {
+		t.Errorf("Retry-After was not honored, got %v", delay)
+	}
+	header.Set("Retry-After", "86400")
+	if delay := policy.backoff(1, header); delay != DefaultMaxRetryAfter {
+		t.Errorf("Retry-After should be capped at %v, got %v", DefaultMaxRetryAfter, delay)
+	}
+	policy.MaxRetryAfter = 5 * time.Second
+	if delay := policy.backoff(1, header); delay != 5*time.Second {
+		t.Errorf("Retry-After should be capped at MaxRetryAfter, got %v", delay)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, MinBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		max *= time.Millisecond
+		delay := policy.backoff(attempt+1, nil)
+		if delay < max/2 || delay > max {
+			t.Errorf("Backoff for attempt %d should be between %v and %v, got %v", attempt+1, max/2, max, delay)
+		}
+	}
+
+	uncapped := RetryPolicy{MaxAttempts: 5, MinBackoff: 100 * time.Millisecond}
+	if delay := uncapped.backoff(4, nil); delay < 400*time.Millisecond || delay > 800*time.Millisecond {
+		t.Errorf("Backoff without a maximum should keep doubling, got %v", delay)
+	}
+}
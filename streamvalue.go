@@ -0,0 +1,116 @@
+// Copyright (c) 2014 Jason Goecke
+// streamvalue.go
+
+package m2x
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// StreamValue is a single value of a stream. The API returns numbers either as
+// JSON numbers or as strings, and both decode into a StreamValue. A value
+// created with Float64Value or Int64Value is posted as a JSON number, one
+// created with StringValue as a JSON string.
+type StreamValue struct {
+	raw    string
+	number bool
+	set    bool
+}
+
+// Float64Value creates a numeric stream value
+//
+//		value := Value{At: NewTimestamp(time.Now()), Value: Float64Value(21.5)}
+func Float64Value(f float64) StreamValue {
+	return StreamValue{raw: strconv.FormatFloat(f, 'f', -1, 64), number: true, set: true}
+}
+
+// Int64Value creates an integer stream value
+func Int64Value(i int64) StreamValue {
+	return StreamValue{raw: strconv.FormatInt(i, 10), number: true, set: true}
+}
+
+// StringValue creates a stream value sent to the API as a string
+func StringValue(s string) StreamValue {
+	return StreamValue{raw: s, set: true}
+}
+
+// IsNull reports whether the value is missing or was null in the response
+func (v StreamValue) IsNull() bool {
+	return !v.set
+}
+
+// String returns the value as it was received, or an empty string when null
+func (v StreamValue) String() string {
+	return v.raw
+}
+
+// Float64 returns the value as a float64
+//
+//		temperature, err := value.Value.Float64()
+func (v StreamValue) Float64() (float64, error) {
+	if !v.set {
+		return 0, errors.New("m2x: stream value is null")
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v.raw), 64)
+	if err != nil {
+		return 0, fmt.Errorf("m2x: stream value %q is not a number", v.raw)
+	}
+	return f, nil
+}
+
+// Int64 returns the value as an int64. Values with a fractional part, such as
+// "21.5", return an error.
+func (v StreamValue) Int64() (int64, error) {
+	if !v.set {
+		return 0, errors.New("m2x: stream value is null")
+	}
+	raw := strings.TrimSpace(v.raw)
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("m2x: stream value %q is not an integer", v.raw)
+	}
+	return int64(f), nil
+}
+
+// MarshalJSON encodes the value as a JSON number, string or null
+func (v StreamValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case !v.set:
+		return []byte("null"), nil
+	case v.number:
+		return []byte(v.raw), nil
+	}
+	return json.Marshal(v.raw)
+}
+
+// UnmarshalJSON decodes the value from a JSON number, string or null
+func (v *StreamValue) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*v = StreamValue{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = StringValue(s)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("m2x: cannot decode %s as a stream value", data)
+	}
+	*v = StreamValue{raw: number.String(), number: true, set: true}
+	return nil
+}
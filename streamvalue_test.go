@@ -0,0 +1,72 @@
+// Copyright (c) 2014 Jason Goecke
+// streamvalue_test.go
+
+package m2x
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStreamValueUnmarshal(t *testing.T) {
+	data := `
+	{ "name": "temperature",
+	  "value": "32",
+	  "min": 22.5,
+	  "max": null,
+	  "unit": { "label": "celcius", "symbol": "C" } }`
+
+	stream, err := parseStream([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := stream.Value.Int64(); err != nil || value != 32 {
+		t.Errorf("A string value did not parse properly: %v", err)
+	}
+	if min, err := stream.Min.Float64(); err != nil || min != 22.5 {
+		t.Errorf("A numeric value did not parse properly: %v", err)
+	}
+	if _, err := stream.Min.Int64(); err == nil {
+		t.Errorf("22.5 should not convert to an integer")
+	}
+	if !stream.Max.IsNull() || stream.Max.String() != "" {
+		t.Errorf("A null value did not parse properly")
+	}
+	if _, err := stream.Max.Float64(); err == nil {
+		t.Errorf("A null value should not convert to a number")
+	}
+
+	var value StreamValue
+	if err := json.Unmarshal([]byte(`{"value":1}`), &value); err == nil {
+		t.Errorf("An object should not decode as a stream value")
+	}
+	json.Unmarshal([]byte(`" 28 "`), &value)
+	if f, err := value.Float64(); err != nil || f != 28 || value.String() != " 28 " {
+		t.Errorf("Padded strings should convert to numbers and keep their raw form")
+	}
+	json.Unmarshal([]byte(`"warm"`), &value)
+	if _, err := value.Float64(); err == nil {
+		t.Errorf("A string that is not a number should not convert")
+	}
+}
+
+func TestStreamValueMarshal(t *testing.T) {
+	values := []Value{
+		{Value: Float64Value(21.5)},
+		{Value: Int64Value(32)},
+		{Value: StringValue("28")},
+		{},
+	}
+	data, _ := json.Marshal(values)
+	expected := `[{"at":null,"value":21.5},{"at":null,"value":32},{"at":null,"value":"28"},{"at":null,"value":null}]`
+	if string(data) != expected {
+		t.Errorf("Stream values did not encode properly: %s", data)
+	}
+
+	var decoded []Value
+	json.Unmarshal(data, &decoded)
+	again, _ := json.Marshal(decoded)
+	if string(again) != expected {
+		t.Errorf("Stream values did not survive a round trip: %s", again)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2014 Jason Goecke
+// timestamp.go
+
+package m2x
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Formats accepted when decoding timestamps returned by the API
+var timestampFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Timestamp is a time as found in the resources of the API, which uses ISO 8601
+// (e.g. "2013-09-09T19:15:00Z"). Empty strings and null decode to the zero
+// Timestamp, which encodes back to null.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps a time.Time into a Timestamp
+//
+//		value := Value{At: NewTimestamp(time.Now()), Value: Float64Value(32)}
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{t}
+}
+
+// ParseTimestamp parses a timestamp in one of the formats used by the API
+//
+//		at, err := ParseTimestamp("2013-09-09T19:15:00Z")
+func ParseTimestamp(value string) (Timestamp, error) {
+	if value == "" {
+		return Timestamp{}, nil
+	}
+	for _, format := range timestampFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return Timestamp{t}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("m2x: cannot parse %q as a timestamp", value)
+}
+
+// MarshalJSON encodes the timestamp in UTC as ISO 8601, or null when it is zero
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON decodes a timestamp from a string or null
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseTimestamp(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2014 Jason Goecke
+// timestamp_test.go
+
+package m2x
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshal(t *testing.T) {
+	data := `
+	{
+	  "start": "2013-09-09T19:15:00Z",
+	  "end": "2013-09-09T19:15:00.123+02:00",
+	  "limit": 100,
+	  "values": [ { "at": "", "value": "32" }, { "at": null, "value": "28" } ]
+	}`
+
+	values, err := parseValues([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !values.Start.Equal(time.Date(2013, 9, 9, 19, 15, 0, 0, time.UTC)) {
+		t.Errorf("Start did not parse properly: %v", values.Start)
+	}
+	if !values.End.Equal(time.Date(2013, 9, 9, 17, 15, 0, 123000000, time.UTC)) {
+		t.Errorf("End did not parse properly: %v", values.End)
+	}
+	if !values.Values[0].At.IsZero() || !values.Values[1].At.IsZero() {
+		t.Errorf("Empty timestamps should be zero")
+	}
+
+	var stamp Timestamp
+	if err := json.Unmarshal([]byte(`"yesterday"`), &stamp); err == nil {
+		t.Errorf("An invalid timestamp should not parse")
+	}
+}
+
+func TestTimestampMarshal(t *testing.T) {
+	value := Value{At: NewTimestamp(time.Date(2013, 9, 9, 21, 15, 0, 0, time.FixedZone("CEST", 7200))), Value: StringValue("32")}
+	data, _ := json.Marshal(value)
+	if string(data) != `{"at":"2013-09-09T19:15:00Z","value":"32"}` {
+		t.Errorf("Timestamp did not encode properly: %s", data)
+	}
+
+	data, _ = json.Marshal(Value{Value: StringValue("32")})
+	if string(data) != `{"at":null,"value":"32"}` {
+		t.Errorf("A zero timestamp should encode as null: %s", data)
+	}
+
+	at, err := ParseTimestamp("2013-09-09T19:15:00Z")
+	if err != nil || at.Unix() != 1378754100 {
+		t.Errorf("ParseTimestamp did not parse properly")
+	}
+}
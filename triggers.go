@@ -4,7 +4,10 @@
 package m2x
 
 import (
+	"context"
 	"encoding/json"
+	"net/url"
+	"strconv"
 )
 
 // Triggers represents a collection of triggers (https://m2x.att.com/developer/documentation/feed#List-Triggers)
@@ -14,30 +17,110 @@ type Triggers struct {
 
 // Trigger represents a trigger
 type Trigger struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Stream      string `json:"stream"`
-	Condition   string `json:"condition"`
-	Value       string `json:"value"`
-	CallbackURL string `json:"callback_url"`
-	URL         string `json:"url"`
-	Status      string `json:"status"`
-	Created     string `json:"created"`
-	Updated     string `json:"updated"`
-}
-
-// Represents a trigger event
-// Due to inconsistent types returned, using a Map (http://forum-m2x.att.com/47j-triggers-not-firing-but-work-on-test#post14953)
-// type TriggerEvent struct {
-// 	Id          string  `json:"feed_id"`
-// 	Stream      string  `json:"stream"`
-// 	Name        string  `json:"trigger_name"`
-// 	Description string  `json:"trigger_description"`
-// 	Condition   string  `json:"condition"`
-// 	Threshold   string  `json:"threshold"`
-// 	Value       float32 `json:"value"`
-// 	At          string  `json:"at"`
-// }
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Stream      string    `json:"stream"`
+	Condition   string    `json:"condition"`
+	Value       string    `json:"value"`
+	CallbackURL string    `json:"callback_url"`
+	URL         string    `json:"url"`
+	Status      string    `json:"status"`
+	Created     Timestamp `json:"created"`
+	Updated     Timestamp `json:"updated"`
+}
+
+// TriggerParams holds the fields used to create or update a trigger.
+// Empty fields are left out, so an update only changes the fields that are set.
+type TriggerParams struct {
+	Name        string `json:"name,omitempty"`
+	Stream      string `json:"stream,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+	Value       string `json:"value,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+// Checks the trigger params, requiring all but the status on creation
+func (p *TriggerParams) validate(create bool) Error {
+	if p == nil {
+		p = &TriggerParams{}
+	}
+	errs := Error{}
+	if create {
+		errs.require("name", p.Name)
+		errs.require("stream", p.Stream)
+		errs.require("condition", p.Condition)
+		errs.require("value", p.Value)
+		errs.require("callback_url", p.CallbackURL)
+	}
+	errs.oneOf("condition", p.Condition, "<", "<=", "=", ">", ">=")
+	errs.oneOf("status", p.Status, "enabled", "disabled")
+	if p.Value != "" {
+		if _, err := strconv.ParseFloat(p.Value, 64); err != nil {
+			errs.add("value", "is not a number")
+		}
+	}
+	if p.CallbackURL != "" {
+		callback, err := url.Parse(p.CallbackURL)
+		if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+			errs.add("callback_url", "is not a valid URL")
+		}
+	}
+	return errs
+}
+
+// TriggerEvent is an event POSTed by the API to the callback URL of a trigger.
+// The API is not consistent in the types it sends
+// (http://forum-m2x.att.com/47j-triggers-not-firing-but-work-on-test#post14953),
+// so the threshold and value are decoded from either JSON strings or numbers.
+type TriggerEvent struct {
+	FeedID      string      `json:"feed_id"`
+	Stream      string      `json:"stream"`
+	Name        string      `json:"trigger_name"`
+	Description string      `json:"trigger_description"`
+	Condition   string      `json:"condition"`
+	Threshold   StreamValue `json:"threshold"`
+	Value       StreamValue `json:"value"`
+	At          Timestamp   `json:"at"`
+
+	// Raw holds every field of the event as decoded by encoding/json, including
+	// those not mapped to the fields above
+	Raw map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON decodes a trigger event. Besides the threshold and value, the
+// text fields also accept numbers, which are kept as sent.
+func (e *TriggerEvent) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		FeedID      StreamValue `json:"feed_id"`
+		Stream      StreamValue `json:"stream"`
+		Name        StreamValue `json:"trigger_name"`
+		Description StreamValue `json:"trigger_description"`
+		Condition   StreamValue `json:"condition"`
+		Threshold   StreamValue `json:"threshold"`
+		Value       StreamValue `json:"value"`
+		At          Timestamp   `json:"at"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = TriggerEvent{
+		FeedID:      fields.FeedID.String(),
+		Stream:      fields.Stream.String(),
+		Name:        fields.Name.String(),
+		Description: fields.Description.String(),
+		Condition:   fields.Condition.String(),
+		Threshold:   fields.Threshold,
+		Value:       fields.Value,
+		At:          fields.At,
+		Raw:         raw,
+	}
+	return nil
+}
 
 // CreateTrigger creates a trigger on a feed stream
 //
@@ -49,22 +132,58 @@ type Trigger struct {
 // 		triggerData["callback_url"] = "http://45bad07a.ngrok.com/streamEvent"
 // 		triggerData["status"] = "enabled"
 // 		trigger, err := client.CreateTrigger(blueprint.Feed, triggerData)
+//
+// Deprecated: use CreateTriggerWithParams, which checks the data before sending it.
 func (c *Client) CreateTrigger(resource string, trigger map[string]string) (*Trigger, *ErrorMessage) {
+	return c.CreateTriggerContext(context.Background(), resource, trigger)
+}
+
+// CreateTriggerContext is like CreateTrigger but uses ctx for the request
+//
+// Deprecated: use CreateTriggerWithParamsContext, which checks the data before sending it.
+func (c *Client) CreateTriggerContext(ctx context.Context, resource string, trigger map[string]string) (*Trigger, *ErrorMessage) {
+	return c.createTrigger(ctx, resource, trigger)
+}
+
+// CreateTriggerWithParams creates a trigger on a feed stream, checking the params first
+//
+//		trigger, err := client.CreateTriggerWithParams(blueprint.Feed, &TriggerParams{
+//			Name:        "foobar",
+//			Stream:      "temperature",
+//			Condition:   ">",
+//			Value:       "30",
+//			CallbackURL: "http://45bad07a.ngrok.com/streamEvent",
+//			Status:      "enabled",
+//		})
+func (c *Client) CreateTriggerWithParams(resource string, params *TriggerParams) (*Trigger, *ErrorMessage) {
+	return c.CreateTriggerWithParamsContext(context.Background(), resource, params)
+}
+
+// CreateTriggerWithParamsContext is like CreateTriggerWithParams but uses ctx for the request
+func (c *Client) CreateTriggerWithParamsContext(ctx context.Context, resource string, params *TriggerParams) (*Trigger, *ErrorMessage) {
+	if errs := params.validate(true); len(errs) > 0 {
+		return nil, validationErrorMessage(errs)
+	}
+	return c.createTrigger(ctx, resource, params)
+}
+
+// Sends the data of CreateTrigger, given either as a map or as params
+func (c *Client) createTrigger(ctx context.Context, resource string, trigger interface{}) (*Trigger, *ErrorMessage) {
 	data, err := json.Marshal(trigger)
 	if err != nil {
 		return nil, simpleErrorMessage(err, 0)
 	}
 
-	result, statusCode, postErr := post(c.APIBase+resource+"/triggers", data)
+	result, statusCode, postErr := c.post(ctx, c.APIBase+resource+"/triggers", data)
 	if postErr != nil {
 		return nil, simpleErrorMessage(postErr, statusCode)
 	}
 
 	if statusCode == 201 {
 		newTrigger := &Trigger{}
-		unmarshalErr := json.Unmarshal(result, &newTrigger)
+		unmarshalErr := json.Unmarshal(result.body, &newTrigger)
 		if unmarshalErr != nil {
-			return nil, simpleErrorMessage(err, statusCode)
+			return nil, simpleErrorMessage(unmarshalErr, statusCode)
 		}
 		return newTrigger, nil
 	}
@@ -75,11 +194,16 @@ func (c *Client) CreateTrigger(resource string, trigger map[string]string) (*Tri
 //
 //		err := client.DeleteTrigger("/feeds/1234", "1235")
 func (c *Client) DeleteTrigger(resource string, id string) *ErrorMessage {
-	result, statusCode, err := delete(c.APIBase+resource+"/triggers/", id)
+	return c.DeleteTriggerContext(context.Background(), resource, id)
+}
+
+// DeleteTriggerContext is like DeleteTrigger but uses ctx for the request
+func (c *Client) DeleteTriggerContext(ctx context.Context, resource string, id string) *ErrorMessage {
+	result, statusCode, err := c.delete(ctx, c.APIBase+resource+"/triggers", id)
 	if err != nil {
 		return simpleErrorMessage(err, statusCode)
 	}
-	if statusCode == 200 {
+	if statusCode == 200 || statusCode == 204 {
 		return nil
 	}
 	return generateErrorMessage(result, statusCode)
@@ -89,12 +213,17 @@ func (c *Client) DeleteTrigger(resource string, id string) *ErrorMessage {
 //
 //		triggers, err := client.Triggers()
 func (c *Client) Triggers(resource string) (*Triggers, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/triggers")
+	return c.TriggersContext(context.Background(), resource)
+}
+
+// TriggersContext is like Triggers but uses ctx for the request
+func (c *Client) TriggersContext(ctx context.Context, resource string) (*Triggers, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/triggers")
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseTriggers(result)
+		data, err := parseTriggers(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -107,12 +236,17 @@ func (c *Client) Triggers(resource string) (*Triggers, *ErrorMessage) {
 //
 //		trigger, err := client.Trigger("/feeds/1234", "1235")
 func (c *Client) Trigger(resource string, id string) (*Trigger, *ErrorMessage) {
-	result, statusCode, err := get(c.APIBase + resource + "/triggers/" + id)
+	return c.TriggerContext(context.Background(), resource, id)
+}
+
+// TriggerContext is like Trigger but uses ctx for the request
+func (c *Client) TriggerContext(ctx context.Context, resource string, id string) (*Trigger, *ErrorMessage) {
+	result, statusCode, err := c.get(ctx, c.APIBase+resource+"/triggers/"+id)
 	if err != nil {
 		return nil, simpleErrorMessage(err, statusCode)
 	}
 	if statusCode == 200 {
-		data, err := parseTrigger(result)
+		data, err := parseTrigger(result.body)
 		if err != nil {
 			return nil, simpleErrorMessage(err, statusCode)
 		}
@@ -126,12 +260,41 @@ func (c *Client) Trigger(resource string, id string) (*Trigger, *ErrorMessage) {
 // 		triggerData["callback_url"] = "http://host.com/streamEvent"
 // 		triggerData["status"] = "disabled"
 // 		err := client.UpdateTrigger("/feeds/1234", "1235", triggerData)
+//
+// Deprecated: use UpdateTriggerWithParams, which checks the data before sending it.
 func (c *Client) UpdateTrigger(resource string, id string, updateData map[string]string) *ErrorMessage {
+	return c.UpdateTriggerContext(context.Background(), resource, id, updateData)
+}
+
+// UpdateTriggerContext is like UpdateTrigger but uses ctx for the request
+//
+// Deprecated: use UpdateTriggerWithParamsContext, which checks the data before sending it.
+func (c *Client) UpdateTriggerContext(ctx context.Context, resource string, id string, updateData map[string]string) *ErrorMessage {
+	return c.updateTrigger(ctx, resource, id, updateData)
+}
+
+// UpdateTriggerWithParams updates the fields of a trigger set in params
+//
+//		err := client.UpdateTriggerWithParams("/feeds/1234", "1235", &TriggerParams{Status: "disabled"})
+func (c *Client) UpdateTriggerWithParams(resource string, id string, params *TriggerParams) *ErrorMessage {
+	return c.UpdateTriggerWithParamsContext(context.Background(), resource, id, params)
+}
+
+// UpdateTriggerWithParamsContext is like UpdateTriggerWithParams but uses ctx for the request
+func (c *Client) UpdateTriggerWithParamsContext(ctx context.Context, resource string, id string, params *TriggerParams) *ErrorMessage {
+	if errs := params.validate(false); len(errs) > 0 {
+		return validationErrorMessage(errs)
+	}
+	return c.updateTrigger(ctx, resource, id, params)
+}
+
+// Sends the data of UpdateTrigger, given either as a map or as params
+func (c *Client) updateTrigger(ctx context.Context, resource string, id string, updateData interface{}) *ErrorMessage {
 	data, err := json.Marshal(updateData)
 	if err != nil {
 		return simpleErrorMessage(err, 0)
 	}
-	result, statusCode, postErr := put(c.APIBase+resource+"/triggers/"+id, data)
+	result, statusCode, postErr := c.put(ctx, c.APIBase+resource+"/triggers/"+id, data)
 	if postErr != nil {
 		return simpleErrorMessage(postErr, statusCode)
 	}
@@ -146,8 +309,13 @@ func (c *Client) UpdateTrigger(resource string, id string, updateData map[string
 //
 //	err := client.TestTrigger("/feeds/1234", "foobar")
 func (c *Client) TestTrigger(resource string, name string) *ErrorMessage {
+	return c.TestTriggerContext(context.Background(), resource, name)
+}
+
+// TestTriggerContext is like TestTrigger but uses ctx for the request
+func (c *Client) TestTriggerContext(ctx context.Context, resource string, name string) *ErrorMessage {
 	var empty []byte
-	result, statusCode, postErr := post(c.APIBase+resource+"/triggers/"+name+"/test", empty)
+	result, statusCode, postErr := c.post(ctx, c.APIBase+resource+"/triggers/"+name+"/test", empty)
 	if postErr != nil {
 		return simpleErrorMessage(postErr, statusCode)
 	}
@@ -178,12 +346,12 @@ func parseTrigger(data []byte) (*Trigger, error) {
 	return trigger, nil
 }
 
-// ParseTriggerEvent parses the JSON for an event returned by a trigger
-// Due to inconsistent types returned, using a Map
-// (http://forum-m2x.att.com/47j-triggers-not-firing-but-work-on-test#post14953)
-// instead of a struct. If resolved, may change back later.
+// DecodeTriggerEvent decodes the JSON for an event POSTed by a trigger
 //
-//		triggerEvent := m2x.ParseTriggerEvent(body)
+//		triggerEvent, err := m2x.DecodeTriggerEvent(body)
+//		if err == nil && !triggerEvent.Value.IsNull() {
+//			temperature, err := triggerEvent.Value.Float64()
+//		}
 //
 // JSON POSTed:
 // 		{
@@ -193,11 +361,24 @@ func parseTrigger(data []byte) (*Trigger, error) {
 //    		"trigger_description":"call < 1",
 //    		"condition":"<",
 //    		"threshold":"1",
-//    		"value":"0",
+//    		"value":0,
 //    		"at":"2014-01-13T14:35:23Z"
 // 		}
+func DecodeTriggerEvent(data []byte) (*TriggerEvent, error) {
+	triggerEvent := &TriggerEvent{}
+	if err := json.Unmarshal(data, triggerEvent); err != nil {
+		return nil, err
+	}
+	return triggerEvent, nil
+}
+
+// ParseTriggerEvent parses the JSON for an event returned by a trigger into a map
+//
+//		triggerEvent, err := m2x.ParseTriggerEvent(body)
+//
+// Deprecated: use DecodeTriggerEvent, which returns a TriggerEvent with the raw
+// map in its Raw field.
 func ParseTriggerEvent(data []byte) (map[string]interface{}, error) {
-	// triggerEvent := &TriggerEvent{}
 	triggerEvent := make(map[string]interface{})
 	err := json.Unmarshal(data, &triggerEvent)
 	if err != nil {
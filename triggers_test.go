@@ -5,7 +5,6 @@ package m2x
 
 import (
 	// "log"
-	"os"
 	"testing"
 	"time"
 )
@@ -92,8 +91,50 @@ func TestParseTriggerEvent(t *testing.T) {
 	}
 }
 
+func TestDecodeTriggerEvent(t *testing.T) {
+	data := `
+	{
+	    "feed_id": "a65689ce7a9a69291c6ed2deda1affad",
+	    "stream": "temperature",
+	    "trigger_name": "foobar",
+	    "trigger_description": "temperature > 30",
+	    "condition": ">",
+	    "threshold": "30",
+	    "value": 31.5,
+	    "at": "2014-01-11T16:14:14Z",
+	    "feed_url": "/feeds/a65689ce7a9a69291c6ed2deda1affad"
+	}`
+
+	triggerEvent, err := DecodeTriggerEvent([]byte(data))
+	if err != nil || triggerEvent.FeedID != "a65689ce7a9a69291c6ed2deda1affad" || triggerEvent.Name != "foobar" || triggerEvent.Condition != ">" {
+		t.Fatalf("Failed to decode trigger event: %v", err)
+	}
+	threshold, _ := triggerEvent.Threshold.Float64()
+	value, _ := triggerEvent.Value.Float64()
+	if threshold != 30 || value != 31.5 {
+		t.Errorf("Threshold and value did not decode properly: %v %v", threshold, value)
+	}
+	if !triggerEvent.At.Equal(time.Date(2014, 1, 11, 16, 14, 14, 0, time.UTC)) {
+		t.Errorf("At did not decode properly: %v", triggerEvent.At)
+	}
+	if triggerEvent.Raw["feed_url"] != "/feeds/a65689ce7a9a69291c6ed2deda1affad" || triggerEvent.Raw["value"] != 31.5 {
+		t.Errorf("Raw fields were not kept: %v", triggerEvent.Raw)
+	}
+
+	triggerEvent, err = DecodeTriggerEvent([]byte(`{"feed_id": 12345, "threshold": 1, "value": "0", "at": null}`))
+	if err != nil || triggerEvent.FeedID != "12345" || triggerEvent.Threshold.String() != "1" || triggerEvent.Value.String() != "0" || !triggerEvent.At.IsZero() {
+		t.Errorf("Failed to decode trigger event with other types: %v %+v", err, triggerEvent)
+	}
+
+	for _, data := range []string{`{"at": "yesterday"}`, `{"value": true}`, `[]`} {
+		if _, err := DecodeTriggerEvent([]byte(data)); err == nil {
+			t.Errorf("Decoding %s should have failed", data)
+		}
+	}
+}
+
 func TestCreateAndListAndUpdateAndDeleteTrigger(t *testing.T) {
-	client := NewClient(os.Getenv("M2X_API_KEY"))
+	client := newCassetteClient(t, "trigger")
 
 	// Create a new batch
 	blueprintData := make(map[string]string)
@@ -162,3 +203,55 @@ func TestCreateAndListAndUpdateAndDeleteTrigger(t *testing.T) {
 		t.Errorf("Did not delete blueprint properly")
 	}
 }
+
+func TestDeleteTrigger(t *testing.T) {
+	for _, statusCode := range []int{200, 204} {
+		server, client, last := newRecordingServer(statusCode, "")
+		if errorMessage := client.DeleteTrigger("/feeds/1234", "1235"); errorMessage != nil {
+			t.Errorf("Deleting a trigger answered with %d should succeed: %v", statusCode, errorMessage)
+		}
+		if method, path, _, _ := last.get(); method != "DELETE" || path != "/feeds/1234/triggers/1235" {
+			t.Errorf("Expected DELETE /feeds/1234/triggers/1235, got %s %s", method, path)
+		}
+		server.Close()
+	}
+}
+
+func TestTriggerParamsValidation(t *testing.T) {
+	valid := &TriggerParams{
+		Name:        "foobar",
+		Stream:      "temperature",
+		Condition:   ">",
+		Value:       "30",
+		CallbackURL: "http://foobar.com",
+		Status:      "enabled",
+	}
+	if errs := valid.validate(true); len(errs) != 0 {
+		t.Errorf("Valid trigger params were rejected: %v", errs)
+	}
+
+	invalid := &TriggerParams{Condition: "~", Value: "thirty", CallbackURL: "foobar.com", Status: "on"}
+	errs := invalid.validate(false)
+	for _, field := range []string{"condition", "value", "callback_url", "status"} {
+		if len(errs[field]) != 1 {
+			t.Errorf("Expected an error for %s, got %v", field, errs)
+		}
+	}
+	if len(errs["name"]) != 0 {
+		t.Errorf("The name should not be required on update")
+	}
+	if errs := invalid.validate(true); len(errs["name"]) != 1 || len(errs["stream"]) != 1 {
+		t.Errorf("The name and stream should be required on creation")
+	}
+}
+
+func TestCreateTriggerMalformedResponse(t *testing.T) {
+	server, client, _ := newRecordingServer(201, `{"id":`)
+	defer server.Close()
+
+	params := &TriggerParams{Name: "foobar", Stream: "temperature", Condition: ">", Value: "30", CallbackURL: "http://foobar.com"}
+	trigger, errorMessage := client.CreateTriggerWithParams("/feeds/1234", params)
+	if trigger != nil || errorMessage == nil || errorMessage.Err == nil {
+		t.Errorf("A malformed response should have been reported: %v", errorMessage)
+	}
+}
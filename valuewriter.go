@@ -0,0 +1,389 @@
+// Copyright (c) 2014 Jason Goecke
+// valuewriter.go
+
+package m2x
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFlushSize is the number of values buffered for a stream before a
+	// ValueWriter posts them
+	DefaultFlushSize = 100
+	// DefaultFlushInterval is how often a ValueWriter posts the values it has
+	// buffered, however few
+	DefaultFlushInterval = time.Second
+	// DefaultOutboxCheckInterval is how often a ValueWriter with an outbox
+	// checks whether the API is reachable again
+	DefaultOutboxCheckInterval = 30 * time.Second
+)
+
+// ErrWriterClosed is returned when writing to a ValueWriter that has been closed
+var ErrWriterClosed = errors.New("m2x: value writer closed")
+
+// WriteError reports values a ValueWriter failed to post
+type WriteError struct {
+	Feed   string
+	Stream string
+	Values []Value
+	Err    *ErrorMessage
+}
+
+// Error describes the failed write
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("m2x: writing %d values to %s stream %s: %v", len(e.Values), e.Feed, e.Stream, e.Err)
+}
+
+// Unwrap returns the error of the failed request
+func (e *WriteError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// WriterOption configures a ValueWriter created with NewValueWriter
+type WriterOption func(*ValueWriter)
+
+// WithFlushSize makes the writer post the values of a stream once n of them
+// are buffered
+func WithFlushSize(n int) WriterOption {
+	return func(w *ValueWriter) {
+		w.flushSize = n
+	}
+}
+
+// WithFlushInterval makes the writer post every buffered value each interval.
+// An interval of zero only posts values on size, Flush and Close.
+func WithFlushInterval(interval time.Duration) WriterOption {
+	return func(w *ValueWriter) {
+		w.flushInterval = interval
+	}
+}
+
+// WithErrorHandler makes the writer call handler with the values of every
+// request that failed. The handler is called from the writer's goroutine and
+// should not block for long.
+func WithErrorHandler(handler func(*WriteError)) WriterOption {
+	return func(w *ValueWriter) {
+		w.onError = handler
+	}
+}
+
+// WithOutbox makes the writer keep the values it could not post because the API
+// was unreachable in outbox, instead of reporting them to the error handler.
+// The writer drains the outbox once Status reports the API is up again. While
+// the outbox holds values, new values are added behind them rather than posted,
+// so no value reaches the API before older ones. The outbox is not closed by
+// the writer.
+func WithOutbox(outbox *Outbox) WriterOption {
+	return func(w *ValueWriter) {
+		w.outbox = outbox
+	}
+}
+
+// WithOutboxCheckInterval sets how often the writer checks whether the API is
+// reachable while its outbox holds values
+func WithOutboxCheckInterval(interval time.Duration) WriterOption {
+	return func(w *ValueWriter) {
+		w.outboxInterval = interval
+	}
+}
+
+// ValueWriter buffers stream values and posts them in the background with
+// UpdateFeedStreamValues, grouped per feed and stream. It may be used from
+// several goroutines. Write never blocks on the API; values are posted in the
+// order their buffers fill up, one request at a time.
+//
+//		writer := m2x.NewValueWriter(client, m2x.WithErrorHandler(func(err *m2x.WriteError) {
+//			log.Println(err)
+//		}))
+//		writer.Write("/feeds/1234", "temperature", m2x.Value{At: m2x.NewTimestamp(time.Now()), Value: m2x.Float64Value(21.5)})
+//		err := writer.Close(ctx)
+type ValueWriter struct {
+	client        *Client
+	flushSize     int
+	flushInterval time.Duration
+	onError       func(*WriteError)
+
+	outbox         *Outbox
+	outboxInterval time.Duration
+	outboxWake     chan struct{}
+	background     sync.WaitGroup
+
+	mu      sync.Mutex
+	buffers map[streamKey][]Value
+	order   []streamKey
+	queue   []valueBatch
+	closed  bool
+
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Identifies the stream of a feed
+type streamKey struct {
+	feed   string
+	stream string
+}
+
+// Values of a stream waiting to be posted
+type valueBatch struct {
+	key    streamKey
+	values []Value
+}
+
+// NewValueWriter creates a ValueWriter posting values through client and starts
+// its background goroutines. Close must be called to post the values still
+// buffered and release them.
+func NewValueWriter(client *Client, options ...WriterOption) *ValueWriter {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &ValueWriter{
+		client:         client,
+		flushSize:      DefaultFlushSize,
+		flushInterval:  DefaultFlushInterval,
+		outboxInterval: DefaultOutboxCheckInterval,
+		buffers:        make(map[streamKey][]Value),
+		wake:           make(chan struct{}, 1),
+		outboxWake:     make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.flushSize <= 0 {
+		w.flushSize = DefaultFlushSize
+	}
+	go w.run()
+	if w.flushInterval > 0 {
+		w.background.Add(1)
+		go w.tick()
+	}
+	if w.outbox != nil && w.outboxInterval > 0 {
+		w.background.Add(1)
+		go w.watchOutbox()
+	}
+	return w
+}
+
+// Write buffers values of a feed stream, queueing them to be posted once the
+// stream has reached the flush size
+func (w *ValueWriter) Write(feed string, stream string, values ...Value) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	key := streamKey{feed: feed, stream: stream}
+	buffer, ok := w.buffers[key]
+	if !ok {
+		w.order = append(w.order, key)
+	}
+	buffer = append(buffer, values...)
+	if len(buffer) >= w.flushSize {
+		w.enqueue(key, buffer)
+		buffer = nil
+	}
+	w.buffers[key] = buffer
+	return nil
+}
+
+// Flush queues every buffered value to be posted without waiting for the
+// flush size or interval
+func (w *ValueWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushBuffers()
+}
+
+// Close stops accepting values and waits until every buffered value has been
+// posted. If ctx is done first, the requests still pending are cancelled and
+// their values kept in the outbox, or reported to the error handler without
+// one, before Close returns ctx.Err().
+func (w *ValueWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.closed = true
+	w.flushBuffers()
+	w.mu.Unlock()
+	close(w.stop)
+	w.signal()
+
+	var err error
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	w.cancel()
+	<-w.done
+	w.background.Wait()
+	return err
+}
+
+// Queues every non-empty buffer, in the order the streams were first written.
+// Must be called with w.mu held.
+func (w *ValueWriter) flushBuffers() {
+	for _, key := range w.order {
+		if buffer := w.buffers[key]; len(buffer) > 0 {
+			w.enqueue(key, buffer)
+		}
+	}
+	w.buffers = make(map[streamKey][]Value)
+	w.order = nil
+}
+
+// Queues the values of a stream in batches of at most the flush size. Must be
+// called with w.mu held.
+func (w *ValueWriter) enqueue(key streamKey, values []Value) {
+	for len(values) > 0 {
+		n := len(values)
+		if n > w.flushSize {
+			n = w.flushSize
+		}
+		w.queue = append(w.queue, valueBatch{key: key, values: values[:n:n]})
+		values = values[n:]
+	}
+	w.signal()
+}
+
+// Wakes the goroutine posting the queued batches
+func (w *ValueWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Posts the queued batches until the writer is closed and the queue is empty
+func (w *ValueWriter) run() {
+	defer close(w.done)
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			closed := w.closed
+			w.mu.Unlock()
+			if closed {
+				return
+			}
+			<-w.wake
+			continue
+		}
+		batch := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+		w.post(batch)
+	}
+}
+
+// Flushes the buffers every interval until the writer is closed
+func (w *ValueWriter) tick() {
+	defer w.background.Done()
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Flush()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Drains the outbox whenever the API is reachable, until the writer is closed
+func (w *ValueWriter) watchOutbox() {
+	defer w.background.Done()
+	ticker := time.NewTicker(w.outboxInterval)
+	defer ticker.Stop()
+	for {
+		if w.outbox.Pending() > 0 && w.reachable() {
+			w.outbox.Drain(func(entry *OutboxEntry) error {
+				return w.send(entry.Feed, entry.Stream, entry.Values, false)
+			})
+		}
+		select {
+		case <-ticker.C:
+		case <-w.outboxWake:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Checks whether the API reports itself up
+func (w *ValueWriter) reachable() bool {
+	status, err := w.client.StatusContext(w.ctx)
+	return err == nil && strings.EqualFold(status.API, "ok")
+}
+
+// Posts a batch, keeping it in the outbox if the API can't be reached. While
+// the outbox holds values the batch is added behind them instead, and the
+// outbox drained, so it is not posted ahead of them.
+func (w *ValueWriter) post(batch valueBatch) {
+	if w.outbox != nil && w.outbox.Pending() > 0 {
+		if err := w.outbox.Append(batch.key.feed, batch.key.stream, batch.values); err == nil {
+			select {
+			case w.outboxWake <- struct{}{}:
+			default:
+			}
+			return
+		}
+	}
+	w.send(batch.key.feed, batch.key.stream, batch.values, true)
+}
+
+// Posts values of a stream. Values that could not be delivered are added to
+// the outbox when store is set, or returned as an error so they stay in the
+// outbox when draining it. Other failures are reported to the error handler.
+func (w *ValueWriter) send(feed string, stream string, values []Value, store bool) error {
+	data := map[string]interface{}{"values": values}
+	errorMessage := w.client.UpdateFeedStreamValuesContext(w.ctx, feed, stream, data)
+	if errorMessage == nil {
+		return nil
+	}
+	if w.outbox != nil && undelivered(errorMessage) {
+		if !store {
+			return errorMessage
+		}
+		if err := w.outbox.Append(feed, stream, values); err == nil {
+			return nil
+		}
+	}
+	if w.onError != nil {
+		w.onError(&WriteError{
+			Feed:   feed,
+			Stream: stream,
+			Values: values,
+			Err:    errorMessage,
+		})
+	}
+	return nil
+}
+
+// Reports whether a request failed because the API could not be reached or
+// was unavailable, rather than because it refused the values
+func undelivered(errorMessage *ErrorMessage) bool {
+	switch errorMessage.StatusCode {
+	case 0:
+		return errorMessage.Err != nil
+	case 429, 500, 502, 503, 504:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2014 Jason Goecke
+// valuewriter_test.go
+
+package m2x
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Starts a server counting the values posted to each stream, answering with
+// the given status code
+func newValuesSink(statusCode int, delay time.Duration) (*httptest.Server, func() (map[string]int, int)) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		data, _ := ioutil.ReadAll(r.Body)
+		body := struct{ Values []Value }{}
+		json.Unmarshal(data, &body)
+		mu.Lock()
+		counts[r.URL.Path] += len(body.Values)
+		requests++
+		mu.Unlock()
+		w.WriteHeader(statusCode)
+	}))
+	return server, func() (map[string]int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := map[string]int{}
+		for path, count := range counts {
+			copied[path] = count
+		}
+		return copied, requests
+	}
+}
+
+func TestValueWriterConcurrentWrites(t *testing.T) {
+	server, posted := newValuesSink(202, 0)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	writer := NewValueWriter(client, WithFlushSize(10), WithFlushInterval(0))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stream := []string{"temperature", "humidity"}[i%2]
+			for j := 0; j < 25; j++ {
+				writer.Write("/feeds/1234", stream, Value{At: NewTimestamp(time.Now()), Value: Int64Value(int64(j))})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := writer.Close(context.Background()); err != nil {
+		t.Errorf("Closing the writer failed: %v", err)
+	}
+
+	counts, requests := posted()
+	if counts["/feeds/1234/streams/temperature/values"] != 100 || counts["/feeds/1234/streams/humidity/values"] != 100 {
+		t.Errorf("Not every value was posted: %v", counts)
+	}
+	if requests != 20 {
+		t.Errorf("Values should have been posted in batches of the flush size, got %d requests", requests)
+	}
+	if err := writer.Write("/feeds/1234", "temperature", Value{}); err != ErrWriterClosed {
+		t.Errorf("Writing to a closed writer should fail")
+	}
+}
+
+func TestValueWriterFlushInterval(t *testing.T) {
+	server, posted := newValuesSink(202, 0)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	writer := NewValueWriter(client, WithFlushInterval(10*time.Millisecond))
+	defer writer.Close(context.Background())
+	writer.Write("/feeds/1234", "temperature", Value{At: NewTimestamp(time.Now()), Value: Int64Value(32)})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if counts, _ := posted(); counts["/feeds/1234/streams/temperature/values"] == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("Buffered values were not posted on the flush interval")
+}
+
+func TestValueWriterErrors(t *testing.T) {
+	server, _ := newValuesSink(422, 0)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	var failed []*WriteError
+	writer := NewValueWriter(client, WithFlushInterval(0), WithErrorHandler(func(err *WriteError) {
+		failed = append(failed, err)
+	}))
+	writer.Write("/feeds/1234", "temperature", Value{At: NewTimestamp(time.Now()), Value: StringValue("hot")})
+	writer.Close(context.Background())
+
+	if len(failed) != 1 || failed[0].Stream != "temperature" || len(failed[0].Values) != 1 {
+		t.Fatalf("The failed write was not reported: %v", failed)
+	}
+	if !errors.Is(failed[0], ErrValidation) {
+		t.Errorf("The error of the request should be wrapped: %v", failed[0])
+	}
+}
+
+func TestValueWriterCloseTimeout(t *testing.T) {
+	server, _ := newValuesSink(202, 200*time.Millisecond)
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	var mu sync.Mutex
+	failed := 0
+	writer := NewValueWriter(client, WithFlushSize(1), WithFlushInterval(0), WithErrorHandler(func(err *WriteError) {
+		mu.Lock()
+		failed += len(err.Values)
+		mu.Unlock()
+	}))
+	for i := 0; i < 5; i++ {
+		writer.Write("/feeds/1234", "temperature", Value{At: NewTimestamp(time.Now()), Value: Int64Value(int64(i))})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := writer.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Close should give up when its context is done: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if failed != 5 {
+		t.Errorf("Values that were not posted should be reported, got %d", failed)
+	}
+}
+
+func TestValueWriterOutbox(t *testing.T) {
+	var mu sync.Mutex
+	up := false
+	posted := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/status" {
+			if up {
+				w.Write([]byte(`{"api":"OK","triggers":"OK"}`))
+			} else {
+				w.Write([]byte(`{"api":"DOWN","triggers":"DOWN"}`))
+			}
+			return
+		}
+		if !up {
+			w.WriteHeader(503)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body := struct{ Values []Value }{}
+		json.Unmarshal(data, &body)
+		posted += len(body.Values)
+		w.WriteHeader(202)
+	}))
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	outbox, err := OpenOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	failed := 0
+	writer := NewValueWriter(client, WithFlushSize(2), WithFlushInterval(0), WithOutbox(outbox), WithOutboxCheckInterval(10*time.Millisecond), WithErrorHandler(func(err *WriteError) {
+		failed++
+	}))
+	for i := 0; i < 6; i++ {
+		writer.Write("/feeds/1234", "temperature", Value{At: NewTimestamp(time.Now()), Value: Int64Value(int64(i))})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for outbox.Pending() != 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if outbox.Pending() != 3 {
+		t.Fatalf("Values should have been kept in the outbox while the API was down, got %d entries", outbox.Pending())
+	}
+
+	mu.Lock()
+	up = true
+	mu.Unlock()
+	for outbox.Pending() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	writer.Close(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if posted != 6 || failed != 0 || outbox.Pending() != 0 {
+		t.Errorf("The outbox was not drained once the API was up: %d posted, %d failed", posted, failed)
+	}
+}
+
+func TestValueWriterOutboxOrder(t *testing.T) {
+	var mu sync.Mutex
+	var posted []int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status" {
+			<-release
+			w.Write([]byte(`{"api":"OK","triggers":"OK"}`))
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body := struct{ Values []Value }{}
+		json.Unmarshal(data, &body)
+		mu.Lock()
+		for _, value := range body.Values {
+			n, _ := value.Value.Int64()
+			posted = append(posted, n)
+		}
+		mu.Unlock()
+		w.WriteHeader(202)
+	}))
+	defer server.Close()
+	client := NewClient("1234")
+	client.APIBase = server.URL
+
+	outbox, err := OpenOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	outbox.Append("/feeds/1234", "temperature", []Value{{At: NewTimestamp(time.Now()), Value: Int64Value(0)}, {At: NewTimestamp(time.Now()), Value: Int64Value(1)}})
+
+	// The outbox is not drained until the status is answered, while new values
+	// are written
+	writer := NewValueWriter(client, WithFlushSize(2), WithFlushInterval(0), WithOutbox(outbox), WithOutboxCheckInterval(10*time.Millisecond))
+	writer.Write("/feeds/1234", "temperature", Value{At: NewTimestamp(time.Now()), Value: Int64Value(2)}, Value{At: NewTimestamp(time.Now()), Value: Int64Value(3)})
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		sent := len(posted)
+		mu.Unlock()
+		if sent > 0 || outbox.Pending() == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	for outbox.Pending() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	writer.Close(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posted) != 4 || posted[0] != 0 || posted[1] != 1 || posted[2] != 2 || posted[3] != 3 {
+		t.Errorf("New values should be posted after the values of the outbox: %v", posted)
+	}
+}